@@ -0,0 +1,191 @@
+package epg
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache status values recorded in Meta["cache"]
+const (
+	// CacheMiss means that there was no cached entry for the request
+	CacheMiss = "miss"
+
+	// CacheHit means that the API responded 304 Not Modified and the cached entry was used
+	CacheHit = "hit"
+
+	// CacheRevalidated means that there was a cached entry, but the API
+	// responded with a new representation that replaced it
+	CacheRevalidated = "revalidated"
+)
+
+// Cache is used by the Client to store response bodies for conditional requests.
+// The key is the request path and encoded query.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+// CacheEntry is a cached response body along with its validators
+type CacheEntry struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// ResponseCache changes the *client cache to the provided Cache
+func ResponseCache(cache Cache) func(*Client) {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+func cacheKey(path string, query url.Values) string {
+	if len(query) > 0 {
+		return path + "?" + Query(query).Encode()
+	}
+
+	return path
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used entry
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates a MemoryCache holding at most size entries
+func NewMemoryCache(size int) *MemoryCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemoryCache{
+		size:    size,
+		ll:      list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry for the given key
+func (mc *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if e, ok := mc.entries[key]; ok {
+		mc.ll.MoveToFront(e)
+
+		return e.Value.(*memoryCacheItem).entry, true
+	}
+
+	return nil, false
+}
+
+// Set stores the entry for the given key, evicting the least recently used entry if full
+func (mc *MemoryCache) Set(key string, entry *CacheEntry) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if e, ok := mc.entries[key]; ok {
+		mc.ll.MoveToFront(e)
+		e.Value.(*memoryCacheItem).entry = entry
+
+		return
+	}
+
+	mc.entries[key] = mc.ll.PushFront(&memoryCacheItem{key, entry})
+
+	if mc.ll.Len() > mc.size {
+		oldest := mc.ll.Back()
+
+		mc.ll.Remove(oldest)
+
+		delete(mc.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.ll.Len()
+}
+
+// DiskCache is a Cache storing each entry as a JSON file in a directory.
+// Entries that cannot be read or written are treated as missing.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a DiskCache in the given directory, creating it if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get returns the entry for the given key
+func (dc *DiskCache) Get(key string) (*CacheEntry, bool) {
+	b, err := ioutil.ReadFile(dc.filename(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CacheEntry
+
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, false
+	}
+
+	return &entry, true
+}
+
+// Set stores the entry for the given key
+func (dc *DiskCache) Set(key string, entry *CacheEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	f, err := ioutil.TempFile(dc.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(b)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	// Rename to make the write atomic for concurrent readers
+	if err == nil {
+		err = os.Rename(f.Name(), dc.filename(key))
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+}
+
+func (dc *DiskCache) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,131 @@
+package epg
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponseCache(t *testing.T) {
+	var (
+		etag        atomic.Value
+		notModified int32
+	)
+
+	etag.Store(`"v1"`)
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			current := etag.Load().(string)
+
+			if r.Header.Get("If-None-Match") == current {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.Header().Set("ETag", current)
+			w.Write(finnishChannel12ResponseXML)
+		}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL), ResponseCache(NewMemoryCache(10)))
+
+	for _, tt := range []struct {
+		etag  string
+		cache string
+	}{
+		{`"v1"`, CacheMiss},
+		{`"v1"`, CacheHit},
+		{`"v2"`, CacheRevalidated},
+		{`"v2"`, CacheHit},
+	} {
+		etag.Store(tt.etag)
+
+		r, err := c.GetChannel(context.Background(), Finland, Finnish, "2017-01-27", "2017-01-27", CanalHD)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := (*r.Meta)["cache"], tt.cache; got != want {
+			t.Fatalf(`(*r.Meta)["cache"] = %v, want %v`, got, want)
+		}
+
+		if got, want := r.Day().Channel(CanalHD).Title, "C More First HD"; got != want {
+			t.Fatalf("r.Day().Channel(CanalHD).Title = %q, want %q", got, want)
+		}
+	}
+
+	if got, want := atomic.LoadInt32(&notModified), int32(2); got != want {
+		t.Fatalf("notModified = %d, want %d", got, want)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	if got, want := cacheKey("/epg/se/sv/2017-01-25", nil), "/epg/se/sv/2017-01-25"; got != want {
+		t.Fatalf("cacheKey = %q, want %q", got, want)
+	}
+
+	if got, want := cacheKey("/epg/se/sv/2017-01-25", url.Values{"genre": {"drama"}}), "/epg/se/sv/2017-01-25?genre=drama"; got != want {
+		t.Fatalf("cacheKey = %q, want %q", got, want)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	mc := NewMemoryCache(2)
+
+	mc.Set("a", &CacheEntry{ETag: "a"})
+	mc.Set("b", &CacheEntry{ETag: "b"})
+
+	// Touch a, making b the least recently used entry
+	if _, ok := mc.Get("a"); !ok {
+		t.Fatalf(`mc.Get("a") not found`)
+	}
+
+	mc.Set("c", &CacheEntry{ETag: "c"})
+
+	if got, want := mc.Len(), 2; got != want {
+		t.Fatalf("mc.Len() = %d, want %d", got, want)
+	}
+
+	if _, ok := mc.Get("b"); ok {
+		t.Fatalf(`mc.Get("b") found, want evicted`)
+	}
+
+	if e, ok := mc.Get("c"); !ok || e.ETag != "c" {
+		t.Fatalf(`mc.Get("c") = %v, %v, want entry c`, e, ok)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dc, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := dc.Get("/epg/se/sv/2017-01-25"); ok {
+		t.Fatalf("dc.Get found entry in empty cache")
+	}
+
+	dc.Set("/epg/se/sv/2017-01-25", &CacheEntry{
+		ETag:         `"v1"`,
+		LastModified: "Wed, 25 Jan 2017 10:00:00 GMT",
+		Body:         emptyEPGResponseXML,
+	})
+
+	e, ok := dc.Get("/epg/se/sv/2017-01-25")
+	if !ok {
+		t.Fatalf("dc.Get did not find entry")
+	}
+
+	if got, want := e.ETag, `"v1"`; got != want {
+		t.Fatalf("e.ETag = %q, want %q", got, want)
+	}
+
+	if got, want := string(e.Body), string(emptyEPGResponseXML); got != want {
+		t.Fatalf("string(e.Body) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+// Code generated by epg-gen-channels; DO NOT EDIT.
+
+package epg
+
+// Channel constants
+//
+// Generated from:
+//
+//   - -pin testdata/channel-pins.txt
+//   - se=testdata/channels-se-2017-01-25.xml
+//   - dk=testdata/channels-dk-2017-01-26.xml
+//   - fi=testdata/channels-fi-2017-01-27.xml
+const (
+	CanalExtra1            = "3"
+	CanalExtra2            = "4"
+	CanalExtra3            = "5"
+	CanalExtraHD           = "7"
+	CanalFilm1             = "8"
+	CanalFilm2             = "9"
+	CanalFilm3             = "11"
+	CanalHD                = "12"
+	CanalPanNordic         = "15"
+	CanalPlusHD            = "17"
+	CanalPlusHitsHD        = "18"
+	CanalSport2            = "21"
+	CanalSport3            = "22"
+	CanalSportFotboll      = "25"
+	CanalSportHockey       = "26"
+	CanalSportNorway       = "27"
+	CanalSportSweden       = "28"
+	CF4                    = "29"
+	SFK                    = "32"
+	SFKBoxer               = "33"
+	SHD                    = "34"
+	SeriesHD               = "52"
+	TV2SportPremium4HD     = "53"
+	CMoreFotbollHockeyKids = "54"
+	CMoreLive2HD           = "65"
+	CMoreLive3HD           = "66"
+	CMoreLive4HD           = "67"
+	CMoreHockeyHD          = "68"
+	CMoreGolfHD            = "70"
+	CMoreGolfDenmarkHD     = "71"
+	CMoreGolf              = "72"
+	CMoreGolfDenmark       = "73"
+	SVT1                   = "74"
+	SVT2                   = "75"
+	TV4                    = "76"
+	TV4Sport               = "78"
+	Sjuan                  = "79"
+	TV12                   = "80"
+	TV4FaktaXL             = "81"
+	TV4Fakta               = "82"
+	TV4Film                = "83"
+	TV4Guld                = "84"
+	TV4Komedi              = "85"
+	SVT24                  = "86"
+	SVTKunskapskanalen     = "87"
+	Barnkanalen            = "88"
+	CMoreStars             = "89"
+	CMoreStarsHD           = "90"
+	CMoreLive5             = "94"
+	CMoreLive5HD           = "95"
+	Sportkanalen           = "97"
+	SportkanalenHD         = "98"
+)
+
+var channels = map[string]string{
+	"CanalExtra1":            CanalExtra1,
+	"CanalExtra2":            CanalExtra2,
+	"CanalExtra3":            CanalExtra3,
+	"CanalExtraHD":           CanalExtraHD,
+	"CanalFilm1":             CanalFilm1,
+	"CanalFilm2":             CanalFilm2,
+	"CanalFilm3":             CanalFilm3,
+	"CanalHD":                CanalHD,
+	"CanalPanNordic":         CanalPanNordic,
+	"CanalPlusHD":            CanalPlusHD,
+	"CanalPlusHitsHD":        CanalPlusHitsHD,
+	"CanalSport2":            CanalSport2,
+	"CanalSport3":            CanalSport3,
+	"CanalSportFotboll":      CanalSportFotboll,
+	"CanalSportHockey":       CanalSportHockey,
+	"CanalSportNorway":       CanalSportNorway,
+	"CanalSportSweden":       CanalSportSweden,
+	"CF4":                    CF4,
+	"SFK":                    SFK,
+	"SFKBoxer":               SFKBoxer,
+	"SHD":                    SHD,
+	"SeriesHD":               SeriesHD,
+	"TV2SportPremium4HD":     TV2SportPremium4HD,
+	"CMoreFotbollHockeyKids": CMoreFotbollHockeyKids,
+	"CMoreLive2HD":           CMoreLive2HD,
+	"CMoreLive3HD":           CMoreLive3HD,
+	"CMoreLive4HD":           CMoreLive4HD,
+	"CMoreHockeyHD":          CMoreHockeyHD,
+	"CMoreGolfHD":            CMoreGolfHD,
+	"CMoreGolfDenmarkHD":     CMoreGolfDenmarkHD,
+	"CMoreGolf":              CMoreGolf,
+	"CMoreGolfDenmark":       CMoreGolfDenmark,
+	"SVT1":                   SVT1,
+	"SVT2":                   SVT2,
+	"TV4":                    TV4,
+	"TV4Sport":               TV4Sport,
+	"Sjuan":                  Sjuan,
+	"TV12":                   TV12,
+	"TV4FaktaXL":             TV4FaktaXL,
+	"TV4Fakta":               TV4Fakta,
+	"TV4Film":                TV4Film,
+	"TV4Guld":                TV4Guld,
+	"TV4Komedi":              TV4Komedi,
+	"SVT24":                  SVT24,
+	"SVTKunskapskanalen":     SVTKunskapskanalen,
+	"Barnkanalen":            Barnkanalen,
+	"CMoreStars":             CMoreStars,
+	"CMoreStarsHD":           CMoreStarsHD,
+	"CMoreLive5":             CMoreLive5,
+	"CMoreLive5HD":           CMoreLive5HD,
+	"Sportkanalen":           Sportkanalen,
+	"SportkanalenHD":         SportkanalenHD,
+}
+
+// channelInfos is the metadata of the channels, their HD and SD variants are paired on init
+var channelInfos = []ChannelInfo{
+	{ID: CanalExtra1, Name: "CanalExtra1", Title: "C More Live 2", Countries: []Country{Sweden}},
+	{ID: CanalExtra2, Name: "CanalExtra2", Title: "C More Live 3", Countries: []Country{Sweden}},
+	{ID: CanalExtra3, Name: "CanalExtra3", Title: "C More Live 4", Countries: []Country{Sweden}},
+	{ID: CanalExtraHD, Name: "CanalExtraHD", Title: "C More Live HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CanalFilm1, Name: "CanalFilm1", Title: "C More First", Countries: []Country{Denmark, Sweden}},
+	{ID: CanalFilm2, Name: "CanalFilm2", Title: "C More Hits", Countries: []Country{Denmark, Sweden}},
+	{ID: CanalFilm3, Name: "CanalFilm3"},
+	{ID: CanalHD, Name: "CanalHD", Title: "C More First HD", IsHD: true, Countries: []Country{Denmark, Finland, Sweden}},
+	{ID: CanalPanNordic, Name: "CanalPanNordic"},
+	{ID: CanalPlusHD, Name: "CanalPlusHD", Title: "C More Sport - Film HD (Boxer)", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CanalPlusHitsHD, Name: "CanalPlusHitsHD", Title: "C More Hits HD", IsHD: true, Countries: []Country{Denmark, Sweden}},
+	{ID: CanalSport2, Name: "CanalSport2"},
+	{ID: CanalSport3, Name: "CanalSport3", Title: "C More Live", Countries: []Country{Sweden}},
+	{ID: CanalSportFotboll, Name: "CanalSportFotboll", Title: "C More Fotboll", Countries: []Country{Sweden}},
+	{ID: CanalSportHockey, Name: "CanalSportHockey", Title: "C More Hockey", Countries: []Country{Sweden}},
+	{ID: CanalSportNorway, Name: "CanalSportNorway"},
+	{ID: CanalSportSweden, Name: "CanalSportSweden", Title: "C More Sport", Countries: []Country{Sweden}},
+	{ID: CF4, Name: "CF4", Title: "C More Series", Countries: []Country{Denmark, Sweden}},
+	{ID: SFK, Name: "SFK", Title: "SF-kanalen", Countries: []Country{Denmark, Sweden}},
+	{ID: SFKBoxer, Name: "SFKBoxer", Title: "C More Sport 1 / SF-kanalen", Countries: []Country{Sweden}},
+	{ID: SHD, Name: "SHD", Title: "C More Sport HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: SeriesHD, Name: "SeriesHD", Title: "C More Series HD", IsHD: true, Countries: []Country{Denmark, Sweden}},
+	{ID: TV2SportPremium4HD, Name: "TV2SportPremium4HD"},
+	{ID: CMoreFotbollHockeyKids, Name: "CMoreFotbollHockeyKids", Title: "C More Fotboll Hockey Stars", Countries: []Country{Sweden}},
+	{ID: CMoreLive2HD, Name: "CMoreLive2HD", Title: "C More Live 2 HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CMoreLive3HD, Name: "CMoreLive3HD", Title: "C More Live 3 HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CMoreLive4HD, Name: "CMoreLive4HD", Title: "C More Live 4 HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CMoreHockeyHD, Name: "CMoreHockeyHD", Title: "C More Hockey HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CMoreGolfHD, Name: "CMoreGolfHD", Title: "C More Golf HD", IsHD: true, Countries: []Country{Sweden}},
+	{ID: CMoreGolfDenmarkHD, Name: "CMoreGolfDenmarkHD", Title: "C More Golf HD", IsHD: true, Countries: []Country{Denmark}},
+	{ID: CMoreGolf, Name: "CMoreGolf"},
+	{ID: CMoreGolfDenmark, Name: "CMoreGolfDenmark"},
+	{ID: SVT1, Name: "SVT1", Title: "SVT1", Countries: []Country{Sweden}},
+	{ID: SVT2, Name: "SVT2", Title: "SVT2", Countries: []Country{Sweden}},
+	{ID: TV4, Name: "TV4", Title: "TV4", Countries: []Country{Sweden}},
+	{ID: TV4Sport, Name: "TV4Sport", Title: "TV4 Sport", Countries: []Country{Sweden}},
+	{ID: Sjuan, Name: "Sjuan", Title: "Sjuan", Countries: []Country{Sweden}},
+	{ID: TV12, Name: "TV12", Title: "TV12", Countries: []Country{Sweden}},
+	{ID: TV4FaktaXL, Name: "TV4FaktaXL", Title: "TV4 FaktaXL", Countries: []Country{Sweden}},
+	{ID: TV4Fakta, Name: "TV4Fakta", Title: "TV4 Fakta", Countries: []Country{Sweden}},
+	{ID: TV4Film, Name: "TV4Film", Title: "TV4 Film", Countries: []Country{Sweden}},
+	{ID: TV4Guld, Name: "TV4Guld", Title: "TV4 Guld", Countries: []Country{Sweden}},
+	{ID: TV4Komedi, Name: "TV4Komedi", Title: "TV4 Komedi", Countries: []Country{Sweden}},
+	{ID: SVT24, Name: "SVT24", Title: "SVT24", Countries: []Country{Sweden}},
+	{ID: SVTKunskapskanalen, Name: "SVTKunskapskanalen", Title: "SVT Kunskapskanalen", Countries: []Country{Sweden}},
+	{ID: Barnkanalen, Name: "Barnkanalen", Title: "Barnkanalen", Countries: []Country{Sweden}},
+	{ID: CMoreStars, Name: "CMoreStars", Title: "C More Stars", Countries: []Country{Denmark, Sweden}},
+	{ID: CMoreStarsHD, Name: "CMoreStarsHD", Title: "C More Stars HD", Countries: []Country{Denmark, Sweden}},
+	{ID: CMoreLive5, Name: "CMoreLive5", Title: "C More Live 5", Countries: []Country{Sweden}},
+	{ID: CMoreLive5HD, Name: "CMoreLive5HD", Title: "C More Live 5 HD", Countries: []Country{Sweden}},
+	{ID: Sportkanalen, Name: "Sportkanalen"},
+	{ID: SportkanalenHD, Name: "SportkanalenHD"},
+}
@@ -0,0 +1,215 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChunkPolicy describes how the Client splits long periods into several
+// smaller requests that are fetched concurrently and merged into one Response
+type ChunkPolicy struct {
+	// Days is the number of days in each chunk (1 means one request per day)
+	Days int
+
+	// Workers is the maximum number of concurrent requests
+	Workers int
+
+	// AllowPartial makes the Client return the chunks that succeeded
+	// (with the errors of the failed chunks in Meta["chunk_errors"])
+	// instead of failing if any chunk fails
+	AllowPartial bool
+}
+
+// Chunked changes the *client to split the periods of GetPeriod,
+// GetChannelGroup and GetChannel according to the provided ChunkPolicy
+func Chunked(policy ChunkPolicy) func(*Client) {
+	return func(c *Client) {
+		c.chunk = policy
+	}
+}
+
+// ChunkError is the error for a chunk of a period that could not be retrieved
+type ChunkError struct {
+	FromDate string
+	ToDate   string
+	Err      error
+}
+
+// Error implements the error interface
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("chunk %s until %s: %v", e.FromDate, e.ToDate, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// chunk is a period fromDate until toDate (inclusive)
+type chunk struct {
+	fromDate string
+	toDate   string
+}
+
+// split splits the period into chunks, returning a single chunk if
+// chunking is disabled or the dates cannot be parsed
+func (p ChunkPolicy) split(fromDate, toDate string) []chunk {
+	from, ferr := time.Parse("2006-01-02", fromDate)
+	to, terr := time.Parse("2006-01-02", toDate)
+
+	if p.Days < 1 || ferr != nil || terr != nil || to.Before(from) {
+		return []chunk{{fromDate, toDate}}
+	}
+
+	var chunks []chunk
+
+	for start := from; !start.After(to); start = start.AddDate(0, 0, p.Days) {
+		end := start.AddDate(0, 0, p.Days-1)
+
+		if end.After(to) {
+			end = to
+		}
+
+		chunks = append(chunks, chunk{DateAtTime(start), DateAtTime(end)})
+	}
+
+	return chunks
+}
+
+func (p ChunkPolicy) workers() int {
+	if p.Workers < 1 {
+		return 1
+	}
+
+	return p.Workers
+}
+
+// getPeriod retrieves the period fromDate until toDate, in chunks if configured
+func (c *Client) getPeriod(ctx context.Context, country Country, fromDate, toDate string, query url.Values, path func(fromDate, toDate string) string) (*Response, error) {
+	chunks := c.chunk.split(fromDate, toDate)
+
+	if len(chunks) < 2 {
+		return c.get(ctx, country, path(fromDate, toDate), query)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		jobs      = make(chan int)
+		responses = make([]*Response, len(chunks))
+		errs      = make([]error, len(chunks))
+	)
+
+	for w := 0; w < c.chunk.workers() && w < len(chunks); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				r, err := c.get(ctx, country, path(chunks[i].fromDate, chunks[i].toDate), query)
+				if err != nil {
+					errs[i] = &ChunkError{chunks[i].fromDate, chunks[i].toDate, err}
+
+					if !c.chunk.AllowPartial {
+						cancel()
+					}
+
+					continue
+				}
+
+				responses[i] = r
+			}
+		}()
+	}
+
+	for i := range chunks {
+		jobs <- i
+	}
+
+	close(jobs)
+
+	wg.Wait()
+
+	var chunkErrors []error
+
+	for _, err := range errs {
+		if err != nil {
+			chunkErrors = append(chunkErrors, err)
+		}
+	}
+
+	if len(chunkErrors) > 0 && (!c.chunk.AllowPartial || len(chunkErrors) == len(chunks)) {
+		return nil, firstError(errs)
+	}
+
+	r := mergeResponses(responses)
+
+	r.Meta = &Meta{
+		"path":   path(fromDate, toDate),
+		"query":  query,
+		"chunks": len(chunks),
+	}
+
+	if len(chunkErrors) > 0 {
+		(*r.Meta)["chunk_errors"] = chunkErrors
+	}
+
+	return r, nil
+}
+
+// firstError returns the first error that is not a consequence of another chunk failing
+func firstError(errs []error) error {
+	var first error
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if first == nil {
+			first = err
+		}
+
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+
+	return first
+}
+
+// mergeResponses merges the days of the (non-nil) responses into one Response
+// ordered by broadcast date, spanning from the earliest FromDate to the latest UntilDate
+func mergeResponses(responses []*Response) *Response {
+	merged := &Response{}
+
+	for _, r := range responses {
+		if r == nil {
+			continue
+		}
+
+		merged.Days = append(merged.Days, r.Days...)
+
+		if merged.FromDate.IsZero() || r.FromDate.Before(merged.FromDate.Time) {
+			merged.FromDate = r.FromDate
+		}
+
+		if r.UntilDate.After(merged.UntilDate.Time) {
+			merged.UntilDate = r.UntilDate
+		}
+	}
+
+	sort.SliceStable(merged.Days, func(i, j int) bool {
+		return merged.Days[i].BroadcastDate.Before(merged.Days[j].BroadcastDate.Time)
+	})
+
+	return merged
+}
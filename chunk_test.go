@@ -0,0 +1,174 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChunkPolicySplit(t *testing.T) {
+	for _, tt := range []struct {
+		policy   ChunkPolicy
+		fromDate string
+		toDate   string
+		want     []chunk
+	}{
+		{ChunkPolicy{}, "2017-01-26", "2017-01-28", []chunk{{"2017-01-26", "2017-01-28"}}},
+		{ChunkPolicy{Days: 1}, "2017-01-26", "2017-01-26", []chunk{{"2017-01-26", "2017-01-26"}}},
+		{ChunkPolicy{Days: 1}, "2017-01-31", "2017-02-02", []chunk{
+			{"2017-01-31", "2017-01-31"},
+			{"2017-02-01", "2017-02-01"},
+			{"2017-02-02", "2017-02-02"},
+		}},
+		{ChunkPolicy{Days: 2}, "2017-01-26", "2017-01-30", []chunk{
+			{"2017-01-26", "2017-01-27"},
+			{"2017-01-28", "2017-01-29"},
+			{"2017-01-30", "2017-01-30"},
+		}},
+		{ChunkPolicy{Days: 1}, "2017-01-28", "2017-01-26", []chunk{{"2017-01-28", "2017-01-26"}}},
+		{ChunkPolicy{Days: 1}, "today", "2017-01-26", []chunk{{"today", "2017-01-26"}}},
+	} {
+		if got := tt.policy.split(tt.fromDate, tt.toDate); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("split(%q, %q) = %v, want %v", tt.fromDate, tt.toDate, got, tt.want)
+		}
+	}
+}
+
+func TestChunked(t *testing.T) {
+	ts, paths := testPeriodServer("2017-01-28")
+	defer ts.Close()
+
+	t.Run("ordered", func(t *testing.T) {
+		c := NewClient(BaseURL(ts.URL), Chunked(ChunkPolicy{Days: 2, Workers: 3}))
+
+		r, err := c.GetPeriod(context.Background(), Sweden, Swedish, "2017-01-20", "2017-01-26")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := len(r.Days), 7; got != want {
+			t.Fatalf("len(r.Days) = %d, want %d", got, want)
+		}
+
+		for i, d := range r.Days {
+			if got, want := DateAtTime(d.BroadcastDate.Time), Date(2017, 1, 20+i); got != want {
+				t.Fatalf("r.Days[%d].BroadcastDate = %s, want %s", i, got, want)
+			}
+		}
+
+		if got, want := DateAtTime(r.FromDate.Time), "2017-01-20"; got != want {
+			t.Fatalf("r.FromDate = %s, want %s", got, want)
+		}
+
+		if got, want := DateAtTime(r.UntilDate.Time), "2017-01-26"; got != want {
+			t.Fatalf("r.UntilDate = %s, want %s", got, want)
+		}
+
+		if got, want := (*r.Meta)["chunks"], 4; got != want {
+			t.Fatalf(`(*r.Meta)["chunks"] = %v, want %v`, got, want)
+		}
+
+		if got, want := (*r.Meta)["path"], "/epg/se/sv/2017-01-20/2017-01-26"; got != want {
+			t.Fatalf(`(*r.Meta)["path"] = %v, want %v`, got, want)
+		}
+
+		if got, want := paths.count("/epg/se/sv/2017-01-26/2017-01-26"), 1; got != want {
+			t.Fatalf("requests for last chunk = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		c := NewClient(BaseURL(ts.URL), Chunked(ChunkPolicy{Days: 1, Workers: 2}))
+
+		_, err := c.GetChannel(context.Background(), Sweden, Swedish, "2017-01-27", "2017-01-29", TV4)
+
+		var ce *ChunkError
+
+		if !errors.As(err, &ce) {
+			t.Fatalf("err = %v, want *ChunkError", err)
+		}
+
+		if got, want := ce.FromDate, "2017-01-28"; got != want {
+			t.Fatalf("ce.FromDate = %q, want %q", got, want)
+		}
+
+		if !errors.Is(err, ErrServerError) {
+			t.Fatalf("errors.Is(err, ErrServerError) = false, want true")
+		}
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		c := NewClient(BaseURL(ts.URL), Chunked(ChunkPolicy{Days: 1, Workers: 2, AllowPartial: true}))
+
+		r, err := c.GetChannelGroup(context.Background(), Sweden, Swedish, "2017-01-27", "2017-01-29", "27")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := len(r.Days), 2; got != want {
+			t.Fatalf("len(r.Days) = %d, want %d", got, want)
+		}
+
+		errs, _ := (*r.Meta)["chunk_errors"].([]error)
+
+		if got, want := len(errs), 1; got != want {
+			t.Fatalf(`len((*r.Meta)["chunk_errors"]) = %d, want %d`, got, want)
+		}
+	})
+}
+
+type pathCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (pc *pathCounter) add(path string) {
+	pc.mu.Lock()
+	pc.counts[path]++
+	pc.mu.Unlock()
+}
+
+func (pc *pathCounter) count(path string) int {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	return pc.counts[path]
+}
+
+// testPeriodServer responds with one empty Day per date in the requested period,
+// and with an internal server error for periods including failDate
+func testPeriodServer(failDate string) (*httptest.Server, *pathCounter) {
+	pc := &pathCounter{counts: map[string]int{}}
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			pc.add(r.URL.Path)
+
+			parts := strings.Split(r.URL.Path, "/")
+
+			from, _ := time.Parse("2006-01-02", parts[4])
+			to, _ := time.Parse("2006-01-02", parts[5])
+
+			var days strings.Builder
+
+			for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+				if DateAtTime(d) == failDate {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				fmt.Fprintf(&days, `<Day BroadcastDate="%sT00:00:00"/>`, DateAtTime(d))
+			}
+
+			fmt.Fprintf(w, `<Epg FromDate="%sT00:00:00" UntilDate="%sT00:00:00">%s</Epg>`, parts[4], parts[5], days.String())
+		}))
+
+	return ts, pc
+}
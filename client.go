@@ -1,6 +1,7 @@
 package epg
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"fmt"
@@ -16,6 +17,15 @@ type Client struct {
 	httpClient *http.Client
 	baseURL    *url.URL
 	userAgent  string
+	retry      RetryPolicy
+	limiter    *limiter
+	cache      Cache
+	flights    *flightGroup
+	chunk      ChunkPolicy
+	hooks      []Hooks
+	tracer     Tracer
+	metrics    Metrics
+	locations  map[Country]*time.Location
 }
 
 // NewClient creates an EPG Client
@@ -61,6 +71,22 @@ func UserAgent(ua string) func(*Client) {
 	}
 }
 
+// TimeZone changes the location used for the naive local timestamps in
+// responses for the provided country, which defaults to country.Location()
+func TimeZone(country Country, loc *time.Location) func(*Client) {
+	return func(c *Client) {
+		if loc == nil {
+			return
+		}
+
+		if c.locations == nil {
+			c.locations = map[Country]*time.Location{}
+		}
+
+		c.locations[country] = loc
+	}
+}
+
 // Date formats a year, month, day into the format yyyy-mm-dd
 func Date(year int, month time.Month, day int) string {
 	return fmt.Sprintf("%04d-%02d-%02d", year, month, day)
@@ -73,22 +99,28 @@ func DateAtTime(t time.Time) string {
 
 // Get retrieves a response for the given country, language and date
 func (c *Client) Get(ctx context.Context, country Country, language Language, date string, attributes ...url.Values) (*Response, error) {
-	return c.get(ctx, c.getPath(country, language, date), c.query(attributes))
+	return c.get(ctx, country, c.getPath(country, language, date), c.query(attributes))
 }
 
 // GetPeriod retrieves the response for the period fromDate until toDate
 func (c *Client) GetPeriod(ctx context.Context, country Country, language Language, fromDate, toDate string, attributes ...url.Values) (*Response, error) {
-	return c.get(ctx, c.getPeriodPath(country, language, fromDate, toDate), c.query(attributes))
+	return c.getPeriod(ctx, country, fromDate, toDate, c.query(attributes), func(fromDate, toDate string) string {
+		return c.getPeriodPath(country, language, fromDate, toDate)
+	})
 }
 
 // GetChannelGroup retrieves the channel group in the period fromDate until toDate
 func (c *Client) GetChannelGroup(ctx context.Context, country Country, language Language, fromDate, toDate, channelGroup string, attributes ...url.Values) (*Response, error) {
-	return c.get(ctx, c.getChannelGroupPath(country, language, fromDate, toDate, channelGroup), c.query(attributes))
+	return c.getPeriod(ctx, country, fromDate, toDate, c.query(attributes), func(fromDate, toDate string) string {
+		return c.getChannelGroupPath(country, language, fromDate, toDate, channelGroup)
+	})
 }
 
 // GetChannel retrieves a channel in the period fromDate until toDate
 func (c *Client) GetChannel(ctx context.Context, country Country, language Language, fromDate, toDate, channelID string, attributes ...url.Values) (*Response, error) {
-	return c.get(ctx, c.getChannelPath(country, language, fromDate, toDate, channelID), c.query(attributes))
+	return c.getPeriod(ctx, country, fromDate, toDate, c.query(attributes), func(fromDate, toDate string) string {
+		return c.getChannelPath(country, language, fromDate, toDate, channelID)
+	})
 }
 
 func (c *Client) getPath(country Country, language Language, date string) string {
@@ -107,43 +139,229 @@ func (c *Client) getChannelPath(country Country, language Language, fromDate, to
 	return fmt.Sprintf("/epg/%s/%s/%s/%s/%s", country, language, fromDate, toDate, channelID)
 }
 
-func (c *Client) get(ctx context.Context, path string, query url.Values) (*Response, error) {
-	req, err := c.request(ctx, path, query)
+// location returns the location used for the naive local timestamps in responses for the country
+func (c *Client) location(country Country) *time.Location {
+	if loc, ok := c.locations[country]; ok {
+		return loc
+	}
+
+	return country.Location()
+}
+
+func (c *Client) get(ctx context.Context, country Country, path string, query url.Values) (*Response, error) {
+	if c.flights == nil {
+		return c.fetch(ctx, country, path, query)
+	}
+
+	r, shared, err := c.flights.do(ctx, cacheKey(path, query), func(ctx context.Context) (*Response, error) {
+		return c.fetch(ctx, country, path, query)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
+	if shared {
+		(*r.Meta)["deduplicated"] = true
+	}
+
+	return r, nil
+}
+
+func (c *Client) fetch(ctx context.Context, country Country, path string, query url.Values) (r *Response, err error) {
+	ctx, span := c.startSpan(ctx, "epg.get")
+	defer func() { span.End(err) }()
+
+	span.SetAttribute("epg.path", path)
+
+	var (
+		key   = cacheKey(path, query)
+		entry *CacheEntry
+	)
+
+	if c.cache != nil {
+		entry, _ = c.cache.Get(key)
+	}
+
+	ex := &Exchange{Path: path, Query: query}
+
+	resp, err := c.do(ctx, ex, entry)
+
+	span.SetAttribute("epg.attempts", ex.Attempt)
+
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := c.decodeResponse(resp)
+	span.SetAttribute("http.status_code", resp.StatusCode)
+
+	body := &countingReadCloser{ReadCloser: resp.Body}
+
+	resp.Body = body
+
+	var cacheStatus string
+
+	if c.cache != nil {
+		if cacheStatus, err = c.cached(resp, key, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+
+	r, err = c.decodeResponse(resp, c.location(country))
+
+	c.observeDuration(MetricDecodeDuration, time.Since(start), nil)
+	c.addCount(MetricBytesRead, body.n, nil)
+
 	if err != nil {
 		return nil, err
 	}
 
+	c.observeResponse(span, r)
+
 	r.Meta = &Meta{
-		"path":  path,
-		"query": query,
+		"path":     path,
+		"query":    query,
+		"attempts": ex.Attempt,
+	}
+
+	if cacheStatus != "" {
+		(*r.Meta)["cache"] = cacheStatus
+	}
+
+	ex.Result = r
+
+	if err := c.afterDecode(ex); err != nil {
+		return nil, err
 	}
 
 	return r, nil
 }
 
-func (c *Client) query(attributes []url.Values) url.Values {
-	if len(attributes) > 0 {
-		return attributes[0]
+// cached replaces the body of a 304 response with the cached entry, and
+// stores the body of a 200 response in the cache. It returns the cache status.
+func (c *Client) cached(resp *http.Response, key string, entry *CacheEntry) (string, error) {
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		_ = resp.Body.Close()
+
+		resp.StatusCode = http.StatusOK
+		resp.Body = ioutil.NopCloser(bytes.NewReader(entry.Body))
+
+		return CacheHit, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := ioutil.ReadAll(resp.Body)
+
+		_ = resp.Body.Close()
+
+		if err != nil {
+			return "", err
+		}
+
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+
+		if etag != "" || lastModified != "" {
+			c.cache.Set(key, &CacheEntry{ETag: etag, LastModified: lastModified, Body: body})
+		}
+
+		if entry != nil {
+			return CacheRevalidated, nil
+		}
+
+		return CacheMiss, nil
+	default:
+		return CacheMiss, nil
 	}
+}
+
+// do sends the request for the exchange, retrying according to the retry
+// policy of the client. It returns the response of the last attempt.
+//
+// If entry is non-nil the request is made conditional on its validators.
+func (c *Client) do(ctx context.Context, ex *Exchange, entry *CacheEntry) (*http.Response, error) {
+	for ex.Attempt = 1; ; ex.Attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := c.request(ctx, ex.Path, ex.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry != nil {
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+
+		ex.Request, ex.Response, ex.Err = req, nil, nil
+
+		if err := c.beforeSend(ex); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+
+		resp, err := c.httpClient.Do(ex.Request)
 
-	return url.Values{}
+		ex.Response, ex.Err = resp, err
+
+		c.observeRequest(ex, time.Since(start))
+
+		if herr := c.afterReceive(ex); herr != nil {
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+
+			return nil, herr
+		}
+
+		if ex.Attempt >= c.retry.attempts() || ctx.Err() != nil || !c.retry.retryable(resp, err) {
+			return resp, err
+		}
+
+		wait, ok := c.retry.backoff(ex.Attempt, resp)
+		if !ok {
+			return resp, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.CopyN(ioutil.Discard, resp.Body, 64)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// query merges all provided attribute sets into one
+func (c *Client) query(attributes []url.Values) url.Values {
+	return Query{}.Merge(attributes...).Values()
 }
 
 func (c *Client) request(ctx context.Context, path string, query url.Values) (*http.Request, error) {
 	rawurl := path
 
 	if len(query) > 0 {
-		rawurl += "?" + query.Encode()
+		rawurl += "?" + Query(query).Encode()
 	}
 
 	rel, err := url.Parse(rawurl)
@@ -164,26 +382,28 @@ func (c *Client) request(ctx context.Context, path string, query url.Values) (*h
 	return req, nil
 }
 
-func (c *Client) decodeResponse(resp *http.Response) (*Response, error) {
+func (c *Client) decodeResponse(resp *http.Response, loc *time.Location) (*Response, error) {
 	defer func() {
 		_, _ = io.CopyN(ioutil.Discard, resp.Body, 64)
 		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		switch resp.StatusCode {
-		case http.StatusNotFound:
-			return nil, ErrNotFound
-		default:
-			return nil, ErrUnknown
-		}
+		return nil, newAPIError(resp)
 	}
 
-	var r Response
+	return decode(resp.Body, loc)
+}
 
-	if err := xml.NewDecoder(resp.Body).Decode(&r); err != nil {
+// decode decodes an Epg document, with the naive local timestamps in loc
+func decode(r io.Reader, loc *time.Location) (*Response, error) {
+	var resp Response
+
+	if err := xml.NewTokenDecoder(localTimes{xml.NewDecoder(r), loc}).Decode(&resp); err != nil {
 		return nil, err
 	}
 
-	return &r, nil
+	resp.in(loc)
+
+	return &resp, nil
 }
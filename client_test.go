@@ -55,6 +55,24 @@ func TestUserAgent(t *testing.T) {
 	}
 }
 
+func TestTimeZone(t *testing.T) {
+	c := NewClient(TimeZone(Finland, Stockholm), TimeZone(Norway, nil))
+
+	for _, tt := range []struct {
+		country Country
+		want    *time.Location
+	}{
+		{Finland, Stockholm},
+		{Norway, Oslo},
+		{Denmark, Copenhagen},
+		{Country("xx"), Stockholm},
+	} {
+		if got := c.location(tt.country); got != tt.want {
+			t.Fatalf("c.location(%q) = %v, want %v", tt.country, got, tt.want)
+		}
+	}
+}
+
 func TestDate(t *testing.T) {
 	for _, tt := range []struct {
 		year  int
@@ -235,6 +253,17 @@ func TestGetChannel(t *testing.T) {
 	if got, want := channel.LogoID, "6636a32b-629c-45a9-a546-505d5cfe8d33"; got != want {
 		t.Fatalf("channel.LogoID = %q, want %q", got, want)
 	}
+
+	// Naive local timestamps are decoded in the time zone of Finland
+	s := channel.Schedules[0]
+
+	if got, want := s.CalendarDate.Time, time.Date(2017, 1, 27, 8, 0, 0, 0, Helsinki); !got.Equal(want) || got.Location() != Helsinki {
+		t.Fatalf("s.CalendarDate = %v, want %v", got, want)
+	}
+
+	if got, want := s.Program.VodStart.Format(time.RFC3339), "2016-09-11T00:00:00+03:00"; got != want {
+		t.Fatalf("s.Program.VodStart = %q, want %q", got, want)
+	}
 }
 
 func TestRequest(t *testing.T) {
@@ -0,0 +1,425 @@
+/*
+Command epg-gen-channels generates the channel constants, the channels
+lookup map and the channel metadata of the epg package from saved EPG XML
+responses.
+
+Usage:
+
+	epg-gen-channels [-o file] [-package name] [-pin file] [country=]file.xml...
+
+Channels are deduplicated by ChannelId and ordered by ID. When the same ID or
+name appears with different values in several files, the last file wins, so
+files should be given from oldest to newest. Titles are kept from older files
+when newer files have none, and a channel is HD if any file marks it as HD.
+
+A file prefixed with a country code, like se=testdata/channels-se-2017-01-25.xml,
+lists channels broadcast in that country.
+
+The -pin file lists channel IDs that win over all files, one "ID Name" per line,
+with # comments. Pinned channels missing from the files are added without
+metadata, and pinned IDs are not reported as moved.
+
+Responses can be saved like this:
+
+	curl -H "Accept: application/xml" "https://api.cmore.se/epg/se/sv/2017-02-13" > testdata/channels-se-2017-02-13.xml
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	var (
+		output = flag.String("o", "", "write the generated code to `file` instead of stdout")
+		pkg    = flag.String("package", "epg", "package `name` of the generated code")
+		pin    = flag.String("pin", "", "read pinned channel IDs from `file`")
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: epg-gen-channels [-o file] [-package name] [-pin file] [country=]file.xml...\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*output, *pkg, *pin, flag.Args(), os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "epg-gen-channels:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, pkg, pin string, paths []string, stdout, stderr io.Writer) error {
+	var (
+		pins    []channel
+		sources = paths
+	)
+
+	if pin != "" {
+		f, err := os.Open(pin)
+		if err != nil {
+			return err
+		}
+
+		pins, err = decodePins(f)
+
+		f.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %v", pin, err)
+		}
+
+		sources = append([]string{"-pin " + pin}, paths...)
+	}
+
+	channels, err := readChannels(paths, pins, func(msg string) {
+		fmt.Fprintln(stderr, "epg-gen-channels: warning:", msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, sources, channels)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := stdout.Write(src)
+		return err
+	}
+
+	return ioutil.WriteFile(output, src, 0644)
+}
+
+type channel struct {
+	ID        string
+	Name      string
+	Title     string
+	IsHD      bool
+	Countries []string
+}
+
+// splitCountry splits an argument like se=testdata/channels-se-2017-01-25.xml into the country and the path
+func splitCountry(arg string) (country, path string) {
+	if i := strings.Index(arg, "="); i > 0 && !strings.ContainsAny(arg[:i], `/\`) {
+		return arg[:i], arg[i+1:]
+	}
+
+	return "", arg
+}
+
+// readChannels reads the channels of all files, deduplicated by ID and ordered by ID,
+// with the IDs of the pinned channels
+func readChannels(args []string, pins []channel, warn func(string)) ([]channel, error) {
+	var (
+		byID   = map[string]channel{}
+		ids    = map[string]string{} // name to ID
+		pinned = map[string]string{} // name to pinned ID
+	)
+
+	for _, p := range pins {
+		pinned[p.Name] = p.ID
+	}
+
+	for _, arg := range args {
+		country, path := splitCountry(arg)
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		channels, err := decodeChannels(f)
+
+		f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+
+		for _, c := range channels {
+			if id, ok := pinned[c.Name]; ok {
+				c.ID = id
+			}
+
+			old, seen := byID[c.ID]
+
+			if seen && old.Name != c.Name {
+				warn(fmt.Sprintf("%s: channel %s renamed from %s to %s", path, c.ID, old.Name, c.Name))
+
+				delete(ids, old.Name)
+			}
+
+			if id, ok := ids[c.Name]; ok && id != c.ID {
+				warn(fmt.Sprintf("%s: channel %s moved from ID %s to %s", path, c.Name, id, c.ID))
+
+				if !seen {
+					old = byID[id] // keep the metadata of the moved channel
+				}
+
+				delete(byID, id)
+			}
+
+			if c.Title == "" {
+				c.Title = old.Title
+			}
+
+			c.IsHD = c.IsHD || old.IsHD
+			c.Countries = old.Countries
+
+			if country != "" && !contains(c.Countries, country) {
+				c.Countries = append(c.Countries, country)
+			}
+
+			byID[c.ID], ids[c.Name] = c, c.ID
+		}
+	}
+
+	for _, p := range pins {
+		if _, ok := ids[p.Name]; ok {
+			continue
+		}
+
+		if old, seen := byID[p.ID]; seen {
+			warn(fmt.Sprintf("pinned channel %s replaces %s with ID %s", p.Name, old.Name, p.ID))
+
+			delete(ids, old.Name)
+		}
+
+		byID[p.ID], ids[p.Name] = p, p.ID
+	}
+
+	channels := make([]channel, 0, len(byID))
+
+	for _, c := range byID {
+		sort.Strings(c.Countries)
+
+		channels = append(channels, c)
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return lessID(channels[i].ID, channels[j].ID)
+	})
+
+	return channels, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeChannels returns the channels of all Channel elements in an EPG XML document
+func decodeChannels(r io.Reader) ([]channel, error) {
+	var (
+		dec      = xml.NewDecoder(r)
+		channels []channel
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return channels, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "Channel" {
+			continue
+		}
+
+		var c channel
+
+		for _, attr := range se.Attr {
+			switch attr.Name.Local {
+			case "ChannelId":
+				c.ID = attr.Value
+			case "Name":
+				c.Name = attr.Value
+			case "Title":
+				c.Title = attr.Value
+			case "IsHd":
+				c.IsHD = attr.Value == "true"
+			}
+		}
+
+		if c.ID == "" || identifier(c.Name) == "" {
+			return nil, fmt.Errorf("offset %d: Channel without ChannelId or Name", dec.InputOffset())
+		}
+
+		channels = append(channels, c)
+	}
+}
+
+// decodePins returns the pinned channels of a file with one "ID Name" per line.
+// Empty lines and lines starting with # are ignored
+func decodePins(r io.Reader) ([]channel, error) {
+	var (
+		sc   = bufio.NewScanner(r)
+		pins []channel
+	)
+
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+
+		if len(fields) != 2 || identifier(strings.TrimSpace(fields[1])) == "" {
+			return nil, fmt.Errorf("line %d: want ID and Name, got %q", n, line)
+		}
+
+		pins = append(pins, channel{ID: fields[0], Name: strings.TrimSpace(fields[1])})
+	}
+
+	return pins, sc.Err()
+}
+
+// identifier turns a channel name into an exported Go identifier
+func identifier(name string) string {
+	var b bytes.Buffer
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+
+	s := []rune(b.String())
+
+	if len(s) == 0 {
+		return ""
+	}
+
+	if !unicode.IsLetter(s[0]) {
+		return "Channel" + string(s)
+	}
+
+	s[0] = unicode.ToUpper(s[0])
+
+	return string(s)
+}
+
+// lessID orders numeric IDs numerically, and other IDs after them
+func lessID(a, b string) bool {
+	na, erra := strconv.Atoi(a)
+	nb, errb := strconv.Atoi(b)
+
+	switch {
+	case erra == nil && errb == nil:
+		return na < nb
+	case erra == nil:
+		return true
+	case errb == nil:
+		return false
+	default:
+		return a < b
+	}
+}
+
+// countryConstants are the names of the Country constants of the epg package
+var countryConstants = map[string]string{
+	"se": "Sweden",
+	"no": "Norway",
+	"dk": "Denmark",
+	"fi": "Finland",
+}
+
+// generate returns the gofmt'ed source of the constants, the channels map and the channel metadata
+func generate(pkg string, paths []string, channels []channel) ([]byte, error) {
+	seen := map[string]string{}
+
+	for _, c := range channels {
+		if name, ok := seen[identifier(c.Name)]; ok {
+			return nil, fmt.Errorf("channels %s and %s have the same constant name %s", name, c.Name, identifier(c.Name))
+		}
+
+		seen[identifier(c.Name)] = c.Name
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by epg-gen-channels; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "// Channel constants\n//\n// Generated from:\n//\n")
+
+	for _, path := range paths {
+		fmt.Fprintf(&b, "//   - %s\n", filepath.ToSlash(path))
+	}
+
+	fmt.Fprintf(&b, "const (\n")
+
+	for _, c := range channels {
+		fmt.Fprintf(&b, "\t%s = %q\n", identifier(c.Name), c.ID)
+	}
+
+	fmt.Fprintf(&b, ")\n\nvar channels = map[string]string{\n")
+
+	for _, c := range channels {
+		fmt.Fprintf(&b, "\t%q: %s,\n", c.Name, identifier(c.Name))
+	}
+
+	fmt.Fprintf(&b, "}\n\n// channelInfos is the metadata of the channels, their HD and SD variants are paired on init\nvar channelInfos = []ChannelInfo{\n")
+
+	for _, c := range channels {
+		fmt.Fprintf(&b, "\t{ID: %s, Name: %q", identifier(c.Name), c.Name)
+
+		if c.Title != "" {
+			fmt.Fprintf(&b, ", Title: %q", c.Title)
+		}
+
+		if c.IsHD {
+			fmt.Fprintf(&b, ", IsHD: true")
+		}
+
+		if len(c.Countries) > 0 {
+			countries := make([]string, len(c.Countries))
+
+			for i, country := range c.Countries {
+				if name, ok := countryConstants[country]; ok {
+					countries[i] = name
+				} else {
+					countries[i] = strconv.Quote(country)
+				}
+			}
+
+			fmt.Fprintf(&b, ", Countries: []Country{%s}", strings.Join(countries, ", "))
+		}
+
+		fmt.Fprintf(&b, "},\n")
+	}
+
+	fmt.Fprintf(&b, "}\n")
+
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIsUpToDate(t *testing.T) {
+	// go generate runs in the package directory, so the paths are relative to it
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.Chdir(filepath.Join("..", "..")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defer os.Chdir(wd)
+
+	var stdout, stderr bytes.Buffer
+
+	args := []string{
+		"se=testdata/channels-se-2017-01-25.xml",
+		"dk=testdata/channels-dk-2017-01-26.xml",
+		"fi=testdata/channels-fi-2017-01-27.xml",
+	}
+
+	if err := run("", "epg", "testdata/channel-pins.txt", args, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, err := ioutil.ReadFile("channels.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(stdout.Bytes(), want) {
+		t.Fatalf("channels.go is not up to date, run go generate")
+	}
+
+	if stderr.Len() > 0 {
+		t.Fatalf("unexpected warnings: %s", stderr.String())
+	}
+}
+
+func TestReadChannels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "epg-gen-channels")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	older := filepath.Join(dir, "older.xml")
+	newer := filepath.Join(dir, "newer.xml")
+
+	write := func(path, doc string) {
+		if err := ioutil.WriteFile(path, []byte(doc), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	write(older, `<Epg><Day>
+		<Channel ChannelId="94" Name="CMoreLive5" Title="C More Live 5"><Schedule/></Channel>
+		<Channel ChannelId="3" Name="CanalExtra1" Title="C More Live 2" IsHd="true"/>
+		<Channel ChannelId="10" Name="OldName"/>
+	</Day></Epg>`)
+
+	write(newer, `<Epg><Day>
+		<Channel ChannelId="91" Name="CMoreLive5"/>
+		<Channel ChannelId="10" Name="NewName"/>
+		<Channel ChannelId="3" Name="CanalExtra1"/>
+		<Channel ChannelId="x1" Name="C More 4K"/>
+	</Day></Epg>`)
+
+	var warnings []string
+
+	channels, err := readChannels([]string{"dk=" + older, "se=" + newer}, nil, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+
+	for _, c := range channels {
+		got = append(got, c.ID+"="+c.Name)
+	}
+
+	if got, want := strings.Join(got, ","), "3=CanalExtra1,10=NewName,91=CMoreLive5,x1=C More 4K"; got != want {
+		t.Fatalf("channels = %q, want %q", got, want)
+	}
+
+	if got, want := len(warnings), 2; got != want {
+		t.Fatalf("len(warnings) = %d, want %d: %q", got, want, warnings)
+	}
+
+	if got, want := channels[2].Title, "C More Live 5"; got != want {
+		t.Fatalf("moved channel Title = %q, want %q", got, want)
+	}
+
+	if c := channels[0]; c.Title != "C More Live 2" || !c.IsHD || strings.Join(c.Countries, ",") != "dk,se" {
+		t.Fatalf("channels[0] = %+v, want title, HD and countries kept from the older file", c)
+	}
+
+	src, err := generate("epg", []string{"older.xml", "newer.xml"}, channels)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"// Code generated by epg-gen-channels; DO NOT EDIT.",
+		"//   - older.xml\n//   - newer.xml\n",
+		"\tCMore4K     = \"x1\"\n",
+		"\t\"C More 4K\":   CMore4K,\n",
+		"\t{ID: CanalExtra1, Name: \"CanalExtra1\", Title: \"C More Live 2\", IsHD: true, Countries: []Country{Denmark, Sweden}},\n",
+		"\t{ID: CMore4K, Name: \"C More 4K\", Countries: []Country{Sweden}},\n",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Fatalf("generated source does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestReadChannelsPinned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "epg-gen-channels")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "channels.xml")
+
+	if err := ioutil.WriteFile(path, []byte(`<Epg><Day>
+		<Channel ChannelId="91" Name="CMoreLive5" Title="C More Live 5"/>
+		<Channel ChannelId="73" Name="CMoreGolfDenmarkHD"/>
+		<Channel ChannelId="3" Name="CanalExtra1"/>
+	</Day></Epg>`), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pins, err := decodePins(strings.NewReader("# comment\n\n94 CMoreLive5\n73 CMoreGolfDenmark\n97 Sportkanalen\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var warnings []string
+
+	channels, err := readChannels([]string{"se=" + path}, pins, func(msg string) {
+		warnings = append(warnings, msg)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+
+	for _, c := range channels {
+		got = append(got, c.ID+"="+c.Name)
+	}
+
+	if got, want := strings.Join(got, ","), "3=CanalExtra1,73=CMoreGolfDenmark,94=CMoreLive5,97=Sportkanalen"; got != want {
+		t.Fatalf("channels = %q, want %q", got, want)
+	}
+
+	if got, want := len(warnings), 1; got != want {
+		t.Fatalf("len(warnings) = %d, want %d: %q", got, want, warnings)
+	}
+
+	if c := channels[2]; c.Title != "C More Live 5" || strings.Join(c.Countries, ",") != "se" {
+		t.Fatalf("channels[2] = %+v, want title and countries of the file", c)
+	}
+
+	for _, doc := range []string{"94", "94 ", "94 - -"} {
+		if _, err := decodePins(strings.NewReader(doc)); err == nil {
+			t.Fatalf("decodePins(%q) returned no error", doc)
+		}
+	}
+}
+
+func TestReadChannelsInvalid(t *testing.T) {
+	for _, doc := range []string{
+		`<Epg><Channel Name="NoID"/></Epg>`,
+		`<Epg><Channel ChannelId="1" Name=" - "/></Epg>`,
+		`<Epg><Channel`,
+	} {
+		if _, err := decodeChannels(strings.NewReader(doc)); err == nil {
+			t.Fatalf("decodeChannels(%q) returned no error", doc)
+		}
+	}
+
+	if _, err := generate("epg", nil, []channel{{ID: "1", Name: "TV 4"}, {ID: "2", Name: "TV4"}}); err == nil {
+		t.Fatalf("expected error for duplicate constant names")
+	}
+}
+
+func TestSplitCountry(t *testing.T) {
+	for _, tt := range []struct {
+		arg     string
+		country string
+		path    string
+	}{
+		{"se=testdata/channels-se-2017-01-25.xml", "se", "testdata/channels-se-2017-01-25.xml"},
+		{"testdata/channels-se-2017-01-25.xml", "", "testdata/channels-se-2017-01-25.xml"},
+		{"testdata/a=b.xml", "", "testdata/a=b.xml"},
+		{"=channels.xml", "", "=channels.xml"},
+	} {
+		country, path := splitCountry(tt.arg)
+
+		if country != tt.country || path != tt.path {
+			t.Fatalf("splitCountry(%q) = %q, %q, want %q, %q", tt.arg, country, path, tt.country, tt.path)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"CMoreStarsHD", "CMoreStarsHD"},
+		{"C More Stars HD", "CMoreStarsHD"},
+		{"svt1", "Svt1"},
+		{"24Kitchen", "Channel24Kitchen"},
+		{"Kanal-5", "Kanal5"},
+		{" ", ""},
+	} {
+		if got := identifier(tt.name); got != tt.want {
+			t.Fatalf("identifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
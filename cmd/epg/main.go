@@ -0,0 +1,427 @@
+/*
+Command epg queries the C More EPG Web API from a terminal.
+
+Usage:
+
+	epg [flags] command [arguments]
+
+The commands are:
+
+	now                          what is airing now on each channel
+	next channel [n]             what is airing now on the channel, and the n (3) following
+	day [date]                   the guide of the date
+	period from to               the guide of the period from until to
+	channel channel [from [to]]  the guide of the channel in the period
+	search query                 the programs with a title containing the query
+	channels                     the channels in the guide of the date
+
+Channels are given as IDs, like 76, or names, like TV4. Dates are given as
+yyyy-mm-dd and default to the -date flag, which defaults to today. The now and
+next commands use the current time of day on the -date.
+
+Examples:
+
+	epg now
+	epg -country dk -genre drama day 2017-01-26
+	epg -format ical -filter livesports channel CanalSportHockey 2017-01-27 2017-02-03
+	epg -prefer hd -format xmltv period 2017-01-25 2017-01-31 > guide.xml
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	epg "github.com/TV4/epg"
+)
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, time.Now())
+
+	switch {
+	case err == flag.ErrHelp:
+		os.Exit(0)
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "epg:", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage is returned by run after the usage has been written to stderr
+var errUsage = errors.New("usage")
+
+type config struct {
+	country  epg.Country
+	language epg.Language
+	date     string
+	query    epg.Query
+	format   string
+	prefer   string
+	now      time.Time
+	at       time.Time // the time of now and next, the current time of day on the date
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer, now time.Time) error {
+	fs := flag.NewFlagSet("epg", flag.ContinueOnError)
+
+	fs.SetOutput(stderr)
+
+	var (
+		country  = fs.String("country", "se", "`country` code: se, no, dk or fi")
+		language = fs.String("language", "", "`language` code: sv, no, da or fi (defaults to the language of the country)")
+		date     = fs.String("date", "", "`date` as yyyy-mm-dd (defaults to today)")
+		filter   = fs.String("filter", "", "comma separated `filters`, e.g. livesports or primetimemovies")
+		genre    = fs.String("genre", "", "comma separated `genres`, e.g. drama or sport")
+		baseURL  = fs.String("base-url", "", "base `URL` of the EPG API (defaults to https://api.cmore.se/)")
+		format   = fs.String("format", "table", "output `format`: table, json, xmltv or ical")
+		prefer   = fs.String("prefer", "", "merge the HD and SD variants of channels into the `variant` hd or sd")
+		timeout  = fs.Duration("timeout", 30*time.Second, "`timeout` of the command")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: epg [flags] command [arguments]\n\n")
+		fmt.Fprintf(fs.Output(), "Commands: now, next, day, period, channel, search and channels\n\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+
+		return errUsage
+	}
+
+	cfg := config{
+		country:  epg.Country(*country),
+		language: epg.Language(*language),
+		date:     *date,
+		format:   *format,
+		prefer:   *prefer,
+		now:      now,
+	}
+
+	if cfg.language == "" {
+		cfg.language = cfg.country.Language()
+	}
+
+	if cfg.date == "" {
+		cfg.date = epg.DateAtTime(now.In(cfg.country.Location()))
+	}
+
+	cfg.at = now
+
+	if t, err := time.ParseInLocation("2006-01-02", cfg.date, cfg.country.Location()); err == nil {
+		n := now.In(t.Location())
+
+		cfg.at = time.Date(t.Year(), t.Month(), t.Day(), n.Hour(), n.Minute(), n.Second(), n.Nanosecond(), t.Location())
+	}
+
+	if cfg.query = cfg.query.Filter(filters(*filter)...).Genre(genres(*genre)...); len(cfg.query) == 0 {
+		cfg.query = nil
+	}
+
+	switch cfg.format {
+	case "table", "json", "xmltv", "ical":
+	default:
+		return fmt.Errorf("unknown format %q", cfg.format)
+	}
+
+	switch cfg.prefer {
+	case "", "hd", "sd":
+	default:
+		return fmt.Errorf("unknown variant %q", cfg.prefer)
+	}
+
+	var options []func(*epg.Client)
+
+	if *baseURL != "" {
+		options = append(options, epg.BaseURL(*baseURL))
+	}
+
+	c := epg.NewClient(options...)
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	cmd, args := fs.Arg(0), fs.Args()[1:]
+
+	command, ok := commands[cmd]
+	if !ok {
+		fmt.Fprintf(stderr, "epg: unknown command %q\n\n", cmd)
+		fs.Usage()
+
+		return errUsage
+	}
+
+	if len(args) < command.min || len(args) > command.max {
+		fmt.Fprintf(stderr, "Usage: epg [flags] %s %s\n", cmd, command.args)
+
+		return errUsage
+	}
+
+	return command.run(ctx, c, cfg, args, stdout)
+}
+
+type command struct {
+	args     string
+	min, max int
+	run      func(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error
+}
+
+var commands = map[string]command{
+	"now":      {"", 0, 0, runNow},
+	"next":     {"channel [n]", 1, 2, runNext},
+	"day":      {"[date]", 0, 1, runDay},
+	"period":   {"from to", 2, 2, runPeriod},
+	"channel":  {"channel [from [to]]", 1, 3, runChannel},
+	"search":   {"query", 1, 1, runSearch},
+	"channels": {"", 0, 0, runChannels},
+}
+
+func runNow(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	r, err := getNow(ctx, c, cfg)
+	if err != nil {
+		return err
+	}
+
+	r = merge(r, cfg)
+
+	d := epg.Day{BroadcastDate: r.Day(cfg.date).BroadcastDate}
+
+	for _, a := range r.OnAt(cfg.at) {
+		a.Channel.Schedules = []epg.Schedule{a.Schedule}
+
+		d.Channels = append(d.Channels, a.Channel)
+	}
+
+	return write(w, cfg, &epg.Response{Days: []epg.Day{d}})
+}
+
+func runNext(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	n := 3
+
+	if len(args) > 1 {
+		var err error
+
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 0 {
+			return fmt.Errorf("invalid number of schedules %q", args[1])
+		}
+	}
+
+	id := channelID(args[0])
+
+	r, err := getNow(ctx, c, cfg)
+	if err != nil {
+		return err
+	}
+
+	var ch epg.Channel
+
+	for _, d := range r.Days {
+		if dc := d.Channel(id); dc.ID != "" {
+			ch = dc
+		}
+	}
+
+	if ch.ID == "" {
+		return fmt.Errorf("channel %q not found in the guide of %s", args[0], cfg.date)
+	}
+
+	now, next := r.NowNext(id, cfg.at, n)
+
+	if now.Start().IsZero() {
+		ch.Schedules = next
+	} else {
+		ch.Schedules = append([]epg.Schedule{now}, next...)
+	}
+
+	return write(w, cfg, &epg.Response{Days: []epg.Day{{BroadcastDate: r.Day(cfg.date).BroadcastDate, Channels: []epg.Channel{ch}}}})
+}
+
+// getNow retrieves the guide of the previous and the current date. Broadcast
+// days run past midnight, so what airs early in the morning is in the guide
+// of the previous date
+func getNow(ctx context.Context, c *epg.Client, cfg config) (*epg.Response, error) {
+	t, err := time.ParseInLocation("2006-01-02", cfg.date, cfg.country.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", cfg.date)
+	}
+
+	return c.GetPeriod(ctx, cfg.country, cfg.language, epg.DateAtTime(t.AddDate(0, 0, -1)), cfg.date, cfg.query.Values())
+}
+
+func runDay(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	date := cfg.date
+
+	if len(args) > 0 {
+		date = args[0]
+	}
+
+	r, err := c.Get(ctx, cfg.country, cfg.language, date, cfg.query.Values())
+	if err != nil {
+		return err
+	}
+
+	return write(w, cfg, merge(r, cfg))
+}
+
+func runPeriod(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	r, err := c.GetPeriod(ctx, cfg.country, cfg.language, args[0], args[1], cfg.query.Values())
+	if err != nil {
+		return err
+	}
+
+	return write(w, cfg, merge(r, cfg))
+}
+
+func runChannel(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	from, to := cfg.date, cfg.date
+
+	if len(args) > 1 {
+		from, to = args[1], args[1]
+	}
+
+	if len(args) > 2 {
+		to = args[2]
+	}
+
+	r, err := c.GetChannel(ctx, cfg.country, cfg.language, from, to, channelID(args[0]), cfg.query.Values())
+	if err != nil {
+		return err
+	}
+
+	return write(w, cfg, merge(r, cfg))
+}
+
+func runSearch(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	r, err := c.Get(ctx, cfg.country, cfg.language, cfg.date, cfg.query.Values())
+	if err != nil {
+		return err
+	}
+
+	query := strings.ToLower(args[0])
+
+	return write(w, cfg, filter(merge(r, cfg), func(s epg.Schedule) bool {
+		for _, title := range []string{s.Program.Title, s.Program.OriginalTitle, s.Program.SeriesTitle, s.Program.EpisodeTitle} {
+			if strings.Contains(strings.ToLower(title), query) {
+				return true
+			}
+		}
+
+		return false
+	}))
+}
+
+func runChannels(ctx context.Context, c *epg.Client, cfg config, args []string, w io.Writer) error {
+	r, err := c.Get(ctx, cfg.country, cfg.language, cfg.date, cfg.query.Values())
+	if err != nil {
+		return err
+	}
+
+	cr := epg.NewChannelRegistry()
+
+	cr.Add(cfg.country, merge(r, cfg))
+
+	return writeChannels(w, cfg, cr.Channels())
+}
+
+// channelID returns the ID of the channel with the given name, or s if it is not a known name
+func channelID(s string) string {
+	if id := epg.ChannelID(s); id != "" {
+		return id
+	}
+
+	return s
+}
+
+// merge merges the HD and SD variants of the channels, if preferred
+func merge(r *epg.Response, cfg config) *epg.Response {
+	switch cfg.prefer {
+	case "hd":
+		return r.MergeVariants(epg.PreferHD)
+	case "sd":
+		return r.MergeVariants(epg.PreferSD)
+	default:
+		return r
+	}
+}
+
+// filter returns the response with only the schedules matching fn, and the channels with any of them
+func filter(r *epg.Response, fn func(epg.Schedule) bool) *epg.Response {
+	f := &epg.Response{FromDate: r.FromDate, UntilDate: r.UntilDate}
+
+	for _, d := range r.Days {
+		fd := epg.Day{BroadcastDate: d.BroadcastDate}
+
+		for _, ch := range d.Channels {
+			var schedules []epg.Schedule
+
+			for _, s := range ch.Schedules {
+				if fn(s) {
+					schedules = append(schedules, s)
+				}
+			}
+
+			if len(schedules) > 0 {
+				ch.Schedules = schedules
+
+				fd.Channels = append(fd.Channels, ch)
+			}
+		}
+
+		if len(fd.Channels) > 0 {
+			f.Days = append(f.Days, fd)
+		}
+	}
+
+	return f
+}
+
+func filters(s string) []epg.Filter {
+	var filters []epg.Filter
+
+	for _, v := range split(s) {
+		filters = append(filters, epg.Filter(v))
+	}
+
+	return filters
+}
+
+func genres(s string) []epg.Genre {
+	var genres []epg.Genre
+
+	for _, v := range split(s) {
+		genres = append(genres, epg.Genre(v))
+	}
+
+	return genres
+}
+
+// split splits a comma separated list, ignoring empty values
+func split(s string) []string {
+	var values []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	epg "github.com/TV4/epg"
+)
+
+const testGuideXML = `<?xml version="1.0"?>
+<Epg FromDate="2017-01-25T00:00:00" UntilDate="2017-01-25T00:00:00">
+  <Day BroadcastDate="2017-01-25T00:00:00">
+    <Channel ChannelId="76" Name="TV4" Title="TV4" IsHd="false">
+      <Schedule ScheduleId="1" CalendarDate="2017-01-25T19:00:00" NextStart="2017-01-25T19:30:00" Type="Tape">
+        <Program ProgramId="10" Title="TV4Nyheterna" Duration="30" />
+      </Schedule>
+      <Schedule ScheduleId="2" CalendarDate="2017-01-25T19:30:00" NextStart="2017-01-25T20:00:00" Type="Tape">
+        <Program ProgramId="11" Title="Sporten" Duration="30" />
+      </Schedule>
+      <Schedule ScheduleId="3" CalendarDate="2017-01-25T20:00:00" NextStart="2017-01-25T21:00:00" Type="Tape">
+        <Program ProgramId="12" Title="Idol" EpisodeTitle="Final" Duration="60" />
+      </Schedule>
+      <Schedule ScheduleId="6" CalendarDate="2017-01-26T00:30:00" NextStart="2017-01-26T02:00:00" Type="Tape">
+        <Program ProgramId="13" Title="Nattfilm" Duration="90" />
+      </Schedule>
+    </Channel>
+    <Channel ChannelId="89" Name="CMoreStars" Title="C More Stars" IsHd="false">
+      <Schedule ScheduleId="4" CalendarDate="2017-01-25T19:00:00" NextStart="2017-01-25T21:00:00" Type="Tape">
+        <Program ProgramId="20" Title="The Matrix" Duration="120" />
+      </Schedule>
+    </Channel>
+    <Channel ChannelId="90" Name="CMoreStarsHD" Title="C More Stars HD" IsHd="false">
+      <Schedule ScheduleId="5" CalendarDate="2017-01-25T19:00:00" NextStart="2017-01-25T21:00:00" Type="Tape">
+        <Program ProgramId="20" Title="The Matrix" Duration="120" />
+      </Schedule>
+    </Channel>
+  </Day>
+</Epg>`
+
+func testServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var paths []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.String())
+
+		switch r.URL.Path {
+		case "/epg/se/sv/2017-01-25", "/epg/se/sv/2017-01-24/2017-01-25", "/epg/se/sv/2017-01-25/2017-01-26", "/epg/se/sv/2017-01-25/2017-01-25/76":
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.Write([]byte(testGuideXML))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return ts, &paths
+}
+
+func testRun(t *testing.T, args ...string) (string, string, []string, error) {
+	t.Helper()
+
+	return testRunAt(t, time.Date(2017, 1, 25, 19, 15, 0, 0, epg.Stockholm), args...)
+}
+
+func testRunAt(t *testing.T, now time.Time, args ...string) (string, string, []string, error) {
+	t.Helper()
+
+	ts, paths := testServer(t)
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	err := run(context.Background(), append([]string{"-base-url", ts.URL}, args...), &stdout, &stderr, now)
+
+	return stdout.String(), stderr.String(), *paths, err
+}
+
+func TestRun(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		path     string
+		contains []string
+		excludes []string
+	}{
+		{
+			args:     []string{"now"},
+			path:     "/epg/se/sv/2017-01-24/2017-01-25",
+			contains: []string{"CHANNEL", "TV4     ", "2017-01-25 19:00  19:30  TV4Nyheterna", "C More Stars HD"},
+			excludes: []string{"Sporten"},
+		},
+		{
+			args:     []string{"-prefer", "sd", "now"},
+			path:     "/epg/se/sv/2017-01-24/2017-01-25",
+			contains: []string{"C More Stars  "},
+			excludes: []string{"C More Stars HD"},
+		},
+		{
+			args:     []string{"next", "TV4", "1"},
+			path:     "/epg/se/sv/2017-01-24/2017-01-25",
+			contains: []string{"TV4Nyheterna", "Sporten"},
+			excludes: []string{"Idol"},
+		},
+		{
+			args:     []string{"-genre", "drama,comedy", "day"},
+			path:     "/epg/se/sv/2017-01-25?genre=drama&genre=comedy",
+			contains: []string{"Idol: Final", "The Matrix"},
+		},
+		{
+			args:     []string{"period", "2017-01-25", "2017-01-26"},
+			path:     "/epg/se/sv/2017-01-25/2017-01-26",
+			contains: []string{"Sporten"},
+		},
+		{
+			args:     []string{"-filter", "livesports", "channel", "TV4", "2017-01-25"},
+			path:     "/epg/se/sv/2017-01-25/2017-01-25/76?filter=livesports",
+			contains: []string{"Sporten"},
+		},
+		{
+			args:     []string{"-prefer", "sd", "channel", "TV4", "2017-01-25"},
+			path:     "/epg/se/sv/2017-01-25/2017-01-25/76",
+			contains: []string{"C More Stars  "},
+			excludes: []string{"C More Stars HD"},
+		},
+		{
+			args:     []string{"search", "matrix"},
+			path:     "/epg/se/sv/2017-01-25",
+			contains: []string{"C More Stars HD", "The Matrix"},
+			excludes: []string{"TV4"},
+		},
+		{
+			args:     []string{"channels"},
+			path:     "/epg/se/sv/2017-01-25",
+			contains: []string{"ID  NAME", "89  CMoreStars    C More Stars         90", "90  CMoreStarsHD  C More Stars HD      89"},
+		},
+		{
+			args:     []string{"-format", "xmltv", "day"},
+			path:     "/epg/se/sv/2017-01-25",
+			contains: []string{"<tv ", `<programme start="20170125190000 +0100"`},
+		},
+		{
+			args:     []string{"-format", "ical", "next", "76"},
+			path:     "/epg/se/sv/2017-01-24/2017-01-25",
+			contains: []string{"BEGIN:VCALENDAR", "X-WR-CALNAME:TV4", "SUMMARY:Idol: Final"},
+		},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			stdout, stderr, paths, err := testRun(t, tt.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v (stderr %q)", err, stderr)
+			}
+
+			if len(paths) != 1 || paths[0] != tt.path {
+				t.Fatalf("paths = %q, want [%q]", paths, tt.path)
+			}
+
+			for _, s := range tt.contains {
+				if !strings.Contains(stdout, s) {
+					t.Fatalf("output does not contain %q:\n%s", s, stdout)
+				}
+			}
+
+			for _, s := range tt.excludes {
+				if strings.Contains(stdout, s) {
+					t.Fatalf("output contains %q:\n%s", s, stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestRunAfterMidnight(t *testing.T) {
+	// The guide of 2017-01-25 runs until the morning of 2017-01-26
+	now := time.Date(2017, 1, 26, 1, 0, 0, 0, epg.Stockholm)
+
+	for _, args := range [][]string{{"now"}, {"next", "TV4"}} {
+		stdout, stderr, paths, err := testRunAt(t, now, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v (stderr %q)", err, stderr)
+		}
+
+		if got, want := strings.Join(paths, ","), "/epg/se/sv/2017-01-25/2017-01-26"; got != want {
+			t.Fatalf("paths = %q, want %q", got, want)
+		}
+
+		if want := "2017-01-26 00:30  02:00  Nattfilm"; !strings.Contains(stdout, want) {
+			t.Fatalf("%s output does not contain %q:\n%s", args[0], want, stdout)
+		}
+	}
+}
+
+func TestRunDate(t *testing.T) {
+	// Now and next use the current time of day on the -date
+	now := time.Date(2017, 3, 1, 19, 15, 0, 0, epg.Stockholm)
+
+	for _, args := range [][]string{{"-date", "2017-01-25", "now"}, {"-date", "2017-01-25", "next", "TV4", "1"}} {
+		stdout, stderr, paths, err := testRunAt(t, now, args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v (stderr %q)", err, stderr)
+		}
+
+		if got, want := strings.Join(paths, ","), "/epg/se/sv/2017-01-24/2017-01-25"; got != want {
+			t.Fatalf("paths = %q, want %q", got, want)
+		}
+
+		if want := "2017-01-25 19:00  19:30  TV4Nyheterna"; !strings.Contains(stdout, want) {
+			t.Fatalf("%s output does not contain %q:\n%s", args[2], want, stdout)
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	stdout, _, _, err := testRun(t, "-format", "json", "day", "2017-01-25")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var r epg.Response
+
+	if err := json.Unmarshal([]byte(stdout), &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(r.Day().Channels), 3; got != want {
+		t.Fatalf("len(r.Day().Channels) = %d, want %d", got, want)
+	}
+
+	stdout, _, _, err = testRun(t, "-format", "json", "channels")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var channels []epg.ChannelInfo
+
+	if err := json.Unmarshal([]byte(stdout), &channels); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(channels), 3; got != want {
+		t.Fatalf("len(channels) = %d, want %d", got, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, tt := range []struct {
+		args  []string
+		usage bool
+	}{
+		{[]string{}, true},
+		{[]string{"-unknown", "now"}, true},
+		{[]string{"unknown"}, true},
+		{[]string{"period", "2017-01-25"}, true},
+		{[]string{"-format", "csv", "now"}, false},
+		{[]string{"-prefer", "4k", "now"}, false},
+		{[]string{"-format", "xmltv", "channels"}, false},
+		{[]string{"next", "TV4", "many"}, false},
+		{[]string{"next", "SVT1"}, false},
+		{[]string{"day", "2017-01-26"}, false},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			_, stderr, _, err := testRun(t, tt.args...)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if got := err == errUsage; got != tt.usage {
+				t.Fatalf("err = %v, want usage error %v", err, tt.usage)
+			}
+
+			if tt.usage && !strings.Contains(stderr, "Usage: epg") {
+				t.Fatalf("stderr does not contain the usage: %q", stderr)
+			}
+		})
+	}
+
+	if _, _, _, err := testRun(t, "day", "2017-01-26"); !errors.Is(err, epg.ErrNotFound) {
+		t.Fatalf("err = %v, want epg.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	epg "github.com/TV4/epg"
+)
+
+// write writes the response to w in the configured format
+func write(w io.Writer, cfg config, r *epg.Response) error {
+	switch cfg.format {
+	case "json":
+		return writeJSON(w, r)
+	case "xmltv":
+		return r.WriteXMLTV(w, epg.XMLTVOptions{Language: string(cfg.language)})
+	case "ical":
+		opts := epg.ICalendarOptions{Timestamp: cfg.now}
+
+		if channels := r.Day().Channels; len(r.Days) == 1 && len(channels) == 1 {
+			opts.Name, opts.Location = channels[0].Title, channels[0].Title
+		}
+
+		return epg.WriteICalendar(w, r.Schedules(func(epg.Channel, epg.Schedule) bool { return true }), opts)
+	default:
+		return writeTable(w, r)
+	}
+}
+
+// writeTable writes one line per schedule, with the channel, start, end and title
+func writeTable(w io.Writer, r *epg.Response) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "CHANNEL\tSTART\tEND\tTITLE")
+
+	for _, d := range r.Days {
+		for _, c := range d.Channels {
+			for _, s := range c.Schedules {
+				start, end := "", ""
+
+				if t := s.Start(); !t.IsZero() {
+					start = t.Format("2006-01-02 15:04")
+				}
+
+				if t := s.End(); !t.IsZero() {
+					end = t.Format("15:04")
+				}
+
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", channelTitle(c), start, end, programTitle(s.Program))
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+// writeChannels writes the channels to w in the configured format
+func writeChannels(w io.Writer, cfg config, channels []epg.ChannelInfo) error {
+	switch cfg.format {
+	case "json":
+		return writeJSON(w, channels)
+	case "table":
+	default:
+		return fmt.Errorf("format %s is not supported for channels", cfg.format)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tTITLE\tHD\tVARIANT")
+
+	for _, ci := range channels {
+		hd, variant := "", ci.HDID+ci.SDID
+
+		if ci.IsHD {
+			hd = "yes"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", ci.ID, ci.Name, ci.Title, hd, variant)
+	}
+
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+func channelTitle(c epg.Channel) string {
+	if c.Title != "" {
+		return c.Title
+	}
+
+	return c.Name
+}
+
+func programTitle(p epg.Program) string {
+	if p.EpisodeTitle != "" && p.EpisodeTitle != p.Title {
+		return p.Title + ": " + p.EpisodeTitle
+	}
+
+	return p.Title
+}
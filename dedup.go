@@ -0,0 +1,111 @@
+package epg
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Deduplicate makes the *client coalesce concurrent identical requests
+// (same path and query) into a single upstream request.
+//
+// Every caller receives its own copy of the *Response, and the upstream
+// request is only canceled once all callers waiting for it have given up.
+// Responses handed to callers that joined an in-flight request have
+// Meta["deduplicated"] set to true.
+func Deduplicate() func(*Client) {
+	return func(c *Client) {
+		c.flights = &flightGroup{}
+	}
+}
+
+// flight is an in-flight (or completed) upstream request
+type flight struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	r       *Response
+	err     error
+}
+
+// flightGroup keeps track of in-flight requests by key
+type flightGroup struct {
+	mu sync.Mutex
+	m  map[string]*flight
+}
+
+// do calls fn once for concurrent callers with the same key.
+// It reports whether the result was shared with an earlier caller.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (*Response, error)) (*Response, bool, error) {
+	g.mu.Lock()
+
+	if g.m == nil {
+		g.m = map[string]*flight{}
+	}
+
+	f, shared := g.m[key]
+
+	if !shared {
+		fctx, cancel := context.WithCancel(detachedContext{ctx})
+
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+
+		g.m[key] = f
+
+		go func() {
+			f.r, f.err = fn(fctx)
+
+			g.mu.Lock()
+			g.forget(key, f)
+			g.mu.Unlock()
+
+			cancel()
+			close(f.done)
+		}()
+	}
+
+	f.waiters++
+
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		if f.err != nil {
+			return nil, shared, f.err
+		}
+
+		return f.r.clone(), shared, nil
+	case <-ctx.Done():
+		g.mu.Lock()
+
+		if f.waiters--; f.waiters == 0 {
+			// Nobody is interested in the result anymore
+			g.forget(key, f)
+			f.cancel()
+		}
+
+		g.mu.Unlock()
+
+		return nil, shared, ctx.Err()
+	}
+}
+
+// forget removes the flight from the group, unless it has already been replaced
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.m[key] == f {
+		delete(g.m, key)
+	}
+}
+
+// detachedContext keeps the values of its parent, but not its deadline or cancelation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (dc detachedContext) Value(key interface{}) interface{} {
+	return dc.parent.Value(key)
+}
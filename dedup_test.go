@@ -0,0 +1,140 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDeduplicate(t *testing.T) {
+	var (
+		calls   int32
+		release = make(chan struct{})
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			w.Write(finnishChannel12ResponseXML)
+		}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL), Deduplicate())
+
+	const n = 10
+
+	var (
+		wg        sync.WaitGroup
+		responses = make([]*Response, n)
+		errs      = make([]error, n)
+	)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	for i := 0; i < n; i++ {
+		ctx := context.Background()
+
+		// The first caller gives up, which must not affect the others
+		if i == 0 {
+			ctx = canceledCtx
+		}
+
+		wg.Add(1)
+
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+
+			responses[i], errs[i] = c.GetChannel(ctx, Finland, Finnish, "2017-01-27", "2017-01-27", CanalHD)
+		}(i, ctx)
+	}
+
+	// Wait for the upstream request and all callers to join it
+	for {
+		c.flights.mu.Lock()
+		f := c.flights.m["/epg/fi/fi/2017-01-27/2017-01-27/12"]
+		joined := f != nil && f.waiters == n
+		c.flights.mu.Unlock()
+
+		if joined {
+			break
+		}
+	}
+
+	cancel()
+	close(release)
+	wg.Wait()
+
+	if got, want := atomic.LoadInt32(&calls), int32(1); got != want {
+		t.Fatalf("calls = %d, want %d", got, want)
+	}
+
+	if !errors.Is(errs[0], context.Canceled) {
+		t.Fatalf("errs[0] = %v, want context.Canceled", errs[0])
+	}
+
+	for i := 1; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("errs[%d] = %v, want nil", i, errs[i])
+		}
+	}
+
+	// Mutating one response must not affect the others
+	responses[1].Days[0].Channels[0].Schedules[0].Program.Title = "Changed"
+
+	if got, want := responses[2].Days[0].Channels[0].Schedules[0].Program.Title, "Sommeren '92"; got != want {
+		t.Fatalf("Program.Title = %q, want %q", got, want)
+	}
+
+	var deduplicated int
+
+	for i := 1; i < n; i++ {
+		if (*responses[i].Meta)["deduplicated"] == true {
+			deduplicated++
+		}
+	}
+
+	// Only the caller that started the upstream request gets a non-deduplicated response
+	if got, want := deduplicated, n-2; got < want {
+		t.Fatalf("deduplicated = %d, want at least %d", got, want)
+	}
+}
+
+func TestDeduplicateAllCanceled(t *testing.T) {
+	var (
+		upstreamStarted  = make(chan struct{})
+		upstreamCanceled = make(chan struct{})
+	)
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			close(upstreamStarted)
+			<-r.Context().Done()
+			close(upstreamCanceled)
+		}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL), Deduplicate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+
+	go func() {
+		_, err := c.Get(ctx, Sweden, Swedish, "2017-01-25")
+		done <- err
+	}()
+
+	<-upstreamStarted
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	<-upstreamCanceled
+}
@@ -46,6 +46,7 @@ http://api.cmore.se/
 package epg
 
 import (
+	"encoding/xml"
 	"errors"
 	"net/url"
 	"strings"
@@ -87,121 +88,27 @@ const (
 	Finnish Language = "fi"
 )
 
-// Channel constants
-//
-// Data retrieved like this:
-//
-//     curl -H "Accept: application/xml" "https://api.cmore.se/epg/se/sv/2017-01-26/2017-02-13" | xmllint --format - |
-//     grep ChannelId | awk -F '"' '{print $2 " " $4 " = \"" $2 "\""}' | sort -n | uniq | awk '{print $2 " " $3 " " $4}' | pbcopy
-//
-const (
-	CanalExtra1            = "3"
-	CanalExtra2            = "4"
-	CanalExtra3            = "5"
-	CanalExtraHD           = "7"
-	CanalFilm1             = "8"
-	CanalFilm2             = "9"
-	CanalFilm3             = "11"
-	CanalHD                = "12"
-	CanalPanNordic         = "15"
-	CanalPlusHD            = "17"
-	CanalPlusHitsHD        = "18"
-	CanalSport2            = "21"
-	CanalSport3            = "22"
-	CanalSportFotboll      = "25"
-	CanalSportHockey       = "26"
-	CanalSportNorway       = "27"
-	CanalSportSweden       = "28"
-	CF4                    = "29"
-	SFK                    = "32"
-	SFKBoxer               = "33"
-	SHD                    = "34"
-	SeriesHD               = "52"
-	TV2SportPremium4HD     = "53"
-	CMoreFotbollHockeyKids = "54"
-	CMoreLive2HD           = "65"
-	CMoreLive3HD           = "66"
-	CMoreLive4HD           = "67"
-	CMoreHockeyHD          = "68"
-	CMoreGolfHD            = "70"
-	CMoreGolfDenmarkHD     = "71"
-	CMoreGolf              = "72"
-	CMoreGolfDenmark       = "73"
-	SVT1                   = "74"
-	SVT2                   = "75"
-	TV4                    = "76"
-	TV4Sport               = "78"
-	Sjuan                  = "79"
-	TV12                   = "80"
-	TV4FaktaXL             = "81"
-	TV4Fakta               = "82"
-	TV4Film                = "83"
-	TV4Guld                = "84"
-	TV4Komedi              = "85"
-	SVT24                  = "86"
-	SVTKunskapskanalen     = "87"
-	Barnkanalen            = "88"
-	CMoreStars             = "89"
-	CMoreStarsHD           = "90"
-	CMoreLive5             = "94"
-	CMoreLive5HD           = "95"
-	Sportkanalen           = "97"
-	SportkanalenHD         = "98"
-)
+// countryLanguages are the languages spoken in each country
+var countryLanguages = map[Country]Language{
+	Sweden:  Swedish,
+	Norway:  Norwegian,
+	Denmark: Danish,
+	Finland: Finnish,
+}
+
+// Language returns the language of the country, or Swedish for unknown countries
+func (c Country) Language() Language {
+	if l, ok := countryLanguages[c]; ok {
+		return l
+	}
 
-var channels = map[string]string{
-	"CanalExtra1":            CanalExtra1,
-	"CanalExtra2":            CanalExtra2,
-	"CanalExtra3":            CanalExtra3,
-	"CanalExtraHD":           CanalExtraHD,
-	"CanalFilm1":             CanalFilm1,
-	"CanalFilm2":             CanalFilm2,
-	"CanalFilm3":             CanalFilm3,
-	"CanalHD":                CanalHD,
-	"CanalPanNordic":         CanalPanNordic,
-	"CanalPlusHD":            CanalPlusHD,
-	"CanalPlusHitsHD":        CanalPlusHitsHD,
-	"CanalSport2":            CanalSport2,
-	"CanalSport3":            CanalSport3,
-	"CanalSportFotboll":      CanalSportFotboll,
-	"CanalSportHockey":       CanalSportHockey,
-	"CanalSportNorway":       CanalSportNorway,
-	"CanalSportSweden":       CanalSportSweden,
-	"CF4":                    CF4,
-	"SFK":                    SFK,
-	"SFKBoxer":               SFKBoxer,
-	"SHD":                    SHD,
-	"SeriesHD":               SeriesHD,
-	"TV2SportPremium4HD":     TV2SportPremium4HD,
-	"CMoreFotbollHockeyKids": CMoreFotbollHockeyKids,
-	"CMoreLive2HD":           CMoreLive2HD,
-	"CMoreLive3HD":           CMoreLive3HD,
-	"CMoreLive4HD":           CMoreLive4HD,
-	"CMoreHockeyHD":          CMoreHockeyHD,
-	"CMoreGolfHD":            CMoreGolfHD,
-	"CMoreGolfDenmarkHD":     CMoreGolfDenmarkHD,
-	"CMoreGolf":              CMoreGolf,
-	"CMoreGolfDenmark":       CMoreGolfDenmark,
-	"SVT1":                   SVT1,
-	"SVT2":                   SVT2,
-	"TV4":                    TV4,
-	"TV4Sport":               TV4Sport,
-	"Sjuan":                  Sjuan,
-	"TV12":                   TV12,
-	"TV4FaktaXL":             TV4FaktaXL,
-	"TV4Fakta":               TV4Fakta,
-	"TV4Film":                TV4Film,
-	"TV4Guld":                TV4Guld,
-	"TV4Komedi":              TV4Komedi,
-	"SVT24":                  SVT24,
-	"SVTKunskapskanalen":     SVTKunskapskanalen,
-	"Barnkanalen":            Barnkanalen,
-	"CMoreStars":             CMoreStars,
-	"CMoreStarsHD":           CMoreStarsHD,
-	"CMoreLive5":             CMoreLive5,
-	"CMoreLive5HD":           CMoreLive5HD,
-	"Sportkanalen":           Sportkanalen,
-	"SportkanalenHD":         SportkanalenHD,
+	return Swedish
+}
+
+//go:generate go run ./cmd/epg-gen-channels -o channels.go -pin testdata/channel-pins.txt se=testdata/channels-se-2017-01-25.xml dk=testdata/channels-dk-2017-01-26.xml fi=testdata/channels-fi-2017-01-27.xml
+
+func init() {
+	pairVariants(channelInfos)
 }
 
 // ChannelID returns the channel ID based on provided channel name
@@ -209,12 +116,57 @@ func ChannelID(name string) string {
 	return channels[name]
 }
 
+// ChannelName returns the channel name based on provided channel ID.
+// Returns empty string if not found
+func ChannelName(id string) string {
+	return knownChannel(id).Name
+}
+
+// knownChannel returns the metadata of the channel with the given id.
+// Returns empty ChannelInfo if not found
+func knownChannel(id string) ChannelInfo {
+	for _, ci := range channelInfos {
+		if ci.ID == id {
+			return ci
+		}
+	}
+
+	return ChannelInfo{}
+}
+
+// ChannelsFor returns the known channels broadcast in the country, ordered by ID
+func ChannelsFor(country Country) []ChannelInfo {
+	var channels []ChannelInfo
+
+	for _, ci := range channelInfos {
+		if ci.BroadcastIn(country) {
+			ci.Countries = append([]Country(nil), ci.Countries...)
+
+			channels = append(channels, ci)
+		}
+	}
+
+	return channels
+}
+
 var (
 	// ErrNotFound means that the resource could not be found
 	ErrNotFound = errors.New("not found")
 
 	// ErrUnknown means that an unexpected error occurred
 	ErrUnknown = errors.New("unknown error")
+
+	// ErrBadRequest means that the API rejected the request as malformed
+	ErrBadRequest = errors.New("bad request")
+
+	// ErrRateLimited means that the API is throttling requests
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrServerError means that the API failed to handle the request
+	ErrServerError = errors.New("server error")
+
+	// ErrInvalidQuery means that a Query contains unsupported attributes
+	ErrInvalidQuery = errors.New("invalid query")
 )
 
 // Response data from the EPG API
@@ -225,6 +177,15 @@ type Response struct {
 	Meta      *Meta `xml:"-" json:"meta,omitempty"`
 }
 
+// MarshalXML encodes the response as the Epg document returned by the API
+func (r Response) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type response Response
+
+	start.Name = xml.Name{Local: "Epg"}
+
+	return e.EncodeElement(response(r), start)
+}
+
 // Day returns the first day in the response, or the (optional) provided date.
 // Returns empty Day if not found
 func (r *Response) Day(dates ...string) Day {
@@ -245,6 +206,49 @@ func (r *Response) Day(dates ...string) Day {
 	return Day{}
 }
 
+// clone returns a deep copy of the response
+func (r *Response) clone() *Response {
+	c := *r
+
+	if r.Days != nil {
+		c.Days = make([]Day, len(r.Days))
+
+		for i, d := range r.Days {
+			c.Days[i] = d.clone()
+		}
+	}
+
+	if r.Meta != nil {
+		m := make(Meta, len(*r.Meta))
+
+		for k, v := range *r.Meta {
+			if q, ok := v.(url.Values); ok {
+				v = cloneValues(q)
+			}
+
+			m[k] = v
+		}
+
+		c.Meta = &m
+	}
+
+	return &c
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+
+	c := make(url.Values, len(v))
+
+	for k, vs := range v {
+		c[k] = append([]string(nil), vs...)
+	}
+
+	return c
+}
+
 // Meta is a type used for request/response metadata
 type Meta map[string]interface{}
 
@@ -266,6 +270,20 @@ func (d Day) Channel(id string) Channel {
 	return Channel{}
 }
 
+func (d Day) clone() Day {
+	if d.Channels != nil {
+		channels := make([]Channel, len(d.Channels))
+
+		for i, c := range d.Channels {
+			channels[i] = c.clone()
+		}
+
+		d.Channels = channels
+	}
+
+	return d
+}
+
 // Channel is a TV channel in the EPG
 type Channel struct {
 	ID          string     `xml:"ChannelId,attr" json:"channel_id"`
@@ -278,56 +296,76 @@ type Channel struct {
 	Schedules   []Schedule `xml:"Schedule" json:"schedules,omitempty"`
 }
 
+func (c Channel) clone() Channel {
+	if c.Schedules != nil {
+		schedules := make([]Schedule, len(c.Schedules))
+
+		for i, s := range c.Schedules {
+			if s.Program.Images != nil {
+				s.Program.Images = append([]Image(nil), s.Program.Images...)
+			}
+
+			schedules[i] = s
+		}
+
+		c.Schedules = schedules
+	}
+
+	return c
+}
+
 // Schedule is the TV program schedule of a channel in the EPG
 type Schedule struct {
-	ID                string  `xml:"ScheduleId,attr" json:"schedule_id"`
-	NextStart         Time    `xml:"NextStart,attr" json:"next_start"`
-	CalendarDate      Time    `xml:"CalendarDate,attr" json:"calendar_date"`
-	IsPremiere        bool    `xml:"IsPremiere,attr" json:"premiere"`
-	IsDubbed          bool    `xml:"IsDubbed,attr" json:"dubbed"`
-	Type              string  `xml:"Type,attr" json:"type"`
-	AlsoAvailableInHD bool    `xml:"AlsoAvailableInHD,attr" json:"also_available_in_hd"`
-	AlsoAvailableIn3D bool    `xml:"AlsoAvailableIn3D,attr" json:"also_available_in_3d"`
-	Is3D              bool    `xml:"Is3D,attr" json:"is_3d"`
-	IsPPV             bool    `xml:"IsPPV,attr" json:"is_ppv"`
-	PlayAssetID       string  `xml:"PlayAssetId1,attr" json:"play_asset_id"`
-	Program           Program `xml:"Program" json:"program"`
+	ID                string       `xml:"ScheduleId,attr" json:"schedule_id"`
+	NextStart         Time         `xml:"NextStart,attr" json:"next_start"`
+	CalendarDate      Time         `xml:"CalendarDate,attr" json:"calendar_date"`
+	IsPremiere        bool         `xml:"IsPremiere,attr" json:"premiere"`
+	IsDubbed          bool         `xml:"IsDubbed,attr" json:"dubbed"`
+	Type              ScheduleType `xml:"Type,attr" json:"type"`
+	AlsoAvailableInHD bool         `xml:"AlsoAvailableInHD,attr" json:"also_available_in_hd"`
+	AlsoAvailableIn3D bool         `xml:"AlsoAvailableIn3D,attr" json:"also_available_in_3d"`
+	Is3D              bool         `xml:"Is3D,attr" json:"is_3d"`
+	IsPPV             bool         `xml:"IsPPV,attr" json:"is_ppv"`
+	PlayAssetID       string       `xml:"PlayAssetId1,attr" json:"play_asset_id"`
+	Program           Program      `xml:"Program" json:"program"`
 }
 
 // Program is the program that is scheduled in the EPG
 type Program struct {
-	ID                       string  `xml:"ProgramId,attr" json:"program_id"`
-	Title                    string  `xml:"Title,attr" json:"title"`
-	OriginalTitle            string  `xml:"OriginalTitle,attr" json:"original_title"`
-	Genre                    string  `xml:"Genre,attr" json:"genre"`
-	GenreKey                 string  `xml:"GenreKey,attr" json:"genre_key"`
-	FirstCalendarDate        Time    `xml:"FirstCalendarDate,attr" json:"first_calendar_date"`
-	LastCalendarDate         Time    `xml:"LastCalendarDate,attr" json:"last_calendar_date"`
-	VodStart                 Time    `xml:"VodStart,attr" json:"vod_start"`
-	VodEnd                   Time    `xml:"VodEnd,attr" json:"vod_end"`
-	Duration                 int     `xml:"Duration,attr" json:"duration"`
-	ContentSourceID          string  `xml:"ContentSourceId,attr" json:"content_source_id"`
-	ProductionYear           string  `xml:"ProductionYear,attr" json:"production_year"`
-	Rating                   string  `xml:"Rating,attr" json:"rating"`
-	Actors                   string  `xml:"Actors,attr" json:"actors"`
-	Directors                string  `xml:"Directors,attr" json:"directors"`
-	Class                    string  `xml:"Class,attr" json:"class"`
-	Type                     string  `xml:"Type,attr" json:"type"`
-	Category                 string  `xml:"Category,attr" json:"category"`
-	IsDubbedVersionAvailable bool    `xml:"IsDubbedVersionAvailable,attr" json:"dubbed_version_available"`
-	VOD                      bool    `xml:"Vod,attr" json:"vod"`
-	OTTBlackout              bool    `xml:"OTTBlackout,attr" json:"ott_blackout"`
-	IsDubbed                 bool    `xml:"IsDubbed,attr" json:"dubbed"`
-	Images                   []Image `xml:"Resources>Image" json:"images"`
-	SeriesID                 string  `xml:"SeriesId,attr" json:"series_id"`
-	SeasonNumber             int     `xml:"SeasonNumber,attr" json:"season_number"`
-	EpisodeNumber            int     `xml:"EpisodeNumber,attr" json:"episode_number"`
-	NumberOfEpisodes         int     `xml:"NumberOfEpisodes,attr" json:"number_of_episodes"`
-	SynopsisExtraShort       string  `xml:"Synopsis>ExtraShort" json:"extra_short"`
-	SynopsisShort            string  `xml:"Synopsis>Short" json:"short"`
-	SynopsisMedium           string  `xml:"Synopsis>Medium" json:"medium"`
-	SynopsisLong             string  `xml:"Synopsis>Long" json:"long"`
-	SynopsisFacts            string  `xml:"Synopsis>Facts" json:"facts"`
+	ID                       string          `xml:"ProgramId,attr" json:"program_id"`
+	Title                    string          `xml:"Title,attr" json:"title"`
+	OriginalTitle            string          `xml:"OriginalTitle,attr" json:"original_title"`
+	EpisodeTitle             string          `xml:"EpisodeTitle,attr" json:"episode_title"`
+	SeriesTitle              string          `xml:"SeriesTitle,attr" json:"series_title"`
+	Genre                    string          `xml:"Genre,attr" json:"genre"`
+	GenreKey                 string          `xml:"GenreKey,attr" json:"genre_key"`
+	FirstCalendarDate        Time            `xml:"FirstCalendarDate,attr" json:"first_calendar_date"`
+	LastCalendarDate         Time            `xml:"LastCalendarDate,attr" json:"last_calendar_date"`
+	VodStart                 Time            `xml:"VodStart,attr" json:"vod_start"`
+	VodEnd                   Time            `xml:"VodEnd,attr" json:"vod_end"`
+	Duration                 int             `xml:"Duration,attr" json:"duration"`
+	ContentSourceID          string          `xml:"ContentSourceId,attr" json:"content_source_id"`
+	ProductionYear           string          `xml:"ProductionYear,attr" json:"production_year"`
+	Rating                   Rating          `xml:"Rating,attr" json:"rating"`
+	Actors                   string          `xml:"Actors,attr" json:"actors"`
+	Directors                string          `xml:"Directors,attr" json:"directors"`
+	Class                    ProgramClass    `xml:"Class,attr" json:"class"`
+	Type                     ProgramType     `xml:"Type,attr" json:"type"`
+	Category                 ProgramCategory `xml:"Category,attr" json:"category"`
+	IsDubbedVersionAvailable bool            `xml:"IsDubbedVersionAvailable,attr" json:"dubbed_version_available"`
+	VOD                      bool            `xml:"Vod,attr" json:"vod"`
+	OTTBlackout              bool            `xml:"OTTBlackout,attr" json:"ott_blackout"`
+	IsDubbed                 bool            `xml:"IsDubbed,attr" json:"dubbed"`
+	Images                   []Image         `xml:"Resources>Image" json:"images"`
+	SeriesID                 string          `xml:"SeriesId,attr" json:"series_id"`
+	SeasonNumber             int             `xml:"SeasonNumber,attr" json:"season_number"`
+	EpisodeNumber            int             `xml:"EpisodeNumber,attr" json:"episode_number"`
+	NumberOfEpisodes         int             `xml:"NumberOfEpisodes,attr" json:"number_of_episodes"`
+	SynopsisExtraShort       string          `xml:"Synopsis>ExtraShort" json:"extra_short"`
+	SynopsisShort            string          `xml:"Synopsis>Short" json:"short"`
+	SynopsisMedium           string          `xml:"Synopsis>Medium" json:"medium"`
+	SynopsisLong             string          `xml:"Synopsis>Long" json:"long"`
+	SynopsisFacts            string          `xml:"Synopsis>Facts" json:"facts"`
 }
 
 // ImageBaseURL is the base URL for images
@@ -339,8 +377,8 @@ var ImageBaseURL = &url.URL{Scheme: "https", Host: "img-cdn-cmore.b17g.services"
 // (format 164 can be used to retrieve the full size image)
 //
 type Image struct {
-	ID       string `xml:"Id,attr" json:"id"`
-	Category string `xml:"Category,attr" json:"category"`
+	ID       string        `xml:"Id,attr" json:"id"`
+	Category ImageCategory `xml:"Category,attr" json:"category"`
 }
 
 // URL returns an *url.URL based on the ImageBaseURL, image ID and provided format
@@ -1,7 +1,11 @@
 package epg
 
 import (
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,6 +30,91 @@ func TestChannelID(t *testing.T) {
 	}
 }
 
+func TestChannelName(t *testing.T) {
+	for _, tt := range []struct {
+		id   string
+		want string
+	}{
+		{"unknown", ""},
+		{TV4, "TV4"},
+		{CMoreStarsHD, "CMoreStarsHD"},
+		{CMoreLive5, "CMoreLive5"},
+	} {
+		if got := ChannelName(tt.id); got != tt.want {
+			t.Fatalf("ChannelName(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+
+		if tt.want != "" && ChannelID(ChannelName(tt.id)) != tt.id {
+			t.Fatalf("ChannelID(ChannelName(%q)) != %q", tt.id, tt.id)
+		}
+	}
+}
+
+func TestChannelsFor(t *testing.T) {
+	danish := ChannelsFor(Denmark)
+
+	var names []string
+
+	for _, ci := range danish {
+		names = append(names, ci.Name)
+	}
+
+	if got, want := strings.Join(names, ","), "CanalFilm1,CanalFilm2,CanalHD,CanalPlusHitsHD,CF4,SFK,SeriesHD,CMoreGolfDenmarkHD,CMoreStars,CMoreStarsHD"; got != want {
+		t.Fatalf("ChannelsFor(Denmark) = %s, want %s", got, want)
+	}
+
+	if got := ChannelsFor(Finland); len(got) != 1 || got[0].ID != CanalHD || got[0].SDID != CanalFilm1 || got[0].Title != "C More First HD" {
+		t.Fatalf("ChannelsFor(Finland) = %+v, want CanalHD paired with CanalFilm1", got)
+	}
+
+	if got := ChannelsFor(Norway); len(got) != 0 {
+		t.Fatalf("ChannelsFor(Norway) = %+v, want no channels", got)
+	}
+
+	danish[0].Countries[0] = Norway
+
+	if got := ChannelsFor(Denmark)[0].Countries[0]; got != Denmark {
+		t.Fatalf("ChannelsFor returned shared Countries, got %q", got)
+	}
+
+	for _, ci := range ChannelsFor(Sweden) {
+		if ci.HDID != "" && ChannelName(ci.HDID) == "" {
+			t.Fatalf("%s has unknown HD variant %q", ci.Name, ci.HDID)
+		}
+	}
+
+	for _, pair := range [][2]string{
+		{CMoreStars, CMoreStarsHD},
+		{CMoreGolf, CMoreGolfHD},
+		{CMoreLive5, CMoreLive5HD},
+		{Sportkanalen, SportkanalenHD},
+		{CanalSport3, CanalExtraHD},
+	} {
+		for _, ci := range ChannelsFor(Sweden) {
+			if ci.ID == pair[0] && ci.HDID != pair[1] {
+				t.Fatalf("%s.HDID = %q, want %q", ci.Name, ci.HDID, pair[1])
+			}
+		}
+	}
+}
+
+func TestCountryLanguage(t *testing.T) {
+	for _, tt := range []struct {
+		country Country
+		want    Language
+	}{
+		{Sweden, Swedish},
+		{Norway, Norwegian},
+		{Denmark, Danish},
+		{Finland, Finnish},
+		{"xx", Swedish},
+	} {
+		if got := tt.country.Language(); got != tt.want {
+			t.Fatalf("%q.Language() = %q, want %q", tt.country, got, tt.want)
+		}
+	}
+}
+
 func TestResponseDay(t *testing.T) {
 	d1 := Day{
 		BroadcastDate: Time{time.Date(2017, time.January, 1, 0, 0, 0, 0, Stockholm)},
@@ -66,6 +155,38 @@ func TestResponseDay(t *testing.T) {
 	}
 }
 
+func TestDecodeEpisodeAndSeriesTitle(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(danishTwoDaysDramaEPGResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var found bool
+
+	for _, c := range r.Day().Channels {
+		for _, s := range c.Schedules {
+			if s.Program.ID != "73394" {
+				continue
+			}
+
+			found = true
+
+			if got, want := s.Program.EpisodeTitle, "Del 4, Hello, Goodbye"; got != want {
+				t.Fatalf("EpisodeTitle = %q, want %q", got, want)
+			}
+
+			if got, want := s.Program.SeriesTitle, "Friday Night Lights"; got != want {
+				t.Fatalf("SeriesTitle = %q, want %q", got, want)
+			}
+		}
+	}
+
+	if !found {
+		t.Fatalf("program 73394 not found")
+	}
+}
+
 func TestDayChannel(t *testing.T) {
 	for _, tt := range []struct {
 		day  Day
@@ -130,3 +251,250 @@ func ExampleNames() {
 	fmt.Printf("%#v\n", Names("August Diehl,Sara Hjort Ditlevsen, Jo Adrian Haavind"))
 	// Output: []string{"August Diehl", "Sara Hjort Ditlevsen", "Jo Adrian Haavind"}
 }
+
+func TestResponseJSONRoundTrip(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":                 emptyEPGResponseXML,
+		"finnish channel 12":    finnishChannel12ResponseXML,
+		"danish two days drama": danishTwoDaysDramaEPGResponseXML,
+		"swedish live sports":   swedishLiveSportsEPGResponseXML,
+		"swedish full day":      swedishFullDayEPGResponseXML,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var want Response
+
+			if err := xml.Unmarshal(data, &want); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got Response
+
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// The time zone is marshaled as an offset, so times are compared in UTC
+			utcTimes(reflect.ValueOf(&want))
+			utcTimes(reflect.ValueOf(&got))
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round-tripped response is not equal to the original")
+			}
+		})
+	}
+}
+
+// utcTimes converts all Time values reachable from v to UTC
+func utcTimes(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			utcTimes(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			utcTimes(v.Index(i))
+		}
+	case reflect.Struct:
+		if t, ok := v.Addr().Interface().(*Time); ok {
+			t.Time = t.UTC()
+
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				utcTimes(v.Field(i))
+			}
+		}
+	}
+}
+
+func TestResponseXMLRoundTrip(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty":                 emptyEPGResponseXML,
+		"finnish channel 12":    finnishChannel12ResponseXML,
+		"danish two days drama": danishTwoDaysDramaEPGResponseXML,
+		"swedish live sports":   swedishLiveSportsEPGResponseXML,
+		"swedish full day":      swedishFullDayEPGResponseXML,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var want Response
+
+			if err := xml.Unmarshal(data, &want); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := xml.Marshal(want)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got Response
+
+			if err := xml.Unmarshal(b, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round-tripped response is not equal to the original")
+			}
+		})
+	}
+}
+
+func TestResponseMarshalXML(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(finnishChannel12ResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := xml.Marshal(&r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := string(b)
+
+	for _, want := range []string{
+		`<Epg FromDate="2017-01-27T00:00:00" UntilDate="2017-01-27T00:00:00">`,
+		`<Day BroadcastDate="2017-01-27T00:00:00">`,
+		`<Channel ChannelId="12" Name="CanalHD"`,
+		`NextStart="2017-01-27T09:35:00" CalendarDate="2017-01-27T08:00:00"`,
+		`VodStart="0001-01-01T00:00:00"`,
+		`<Resources><Image Id="f16a9f48-b1d4-4c5a-adaf-b9537491cbce" Category="Primary"></Image>`,
+		`<Facts>Draama, 2015.</Facts></Synopsis>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("XML output does not contain %q", want)
+		}
+	}
+}
+
+func TestResponseMarshalXMLFixtures(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"finnish channel 12":    finnishChannel12ResponseXML,
+		"danish two days drama": danishTwoDaysDramaEPGResponseXML,
+		"swedish live sports":   swedishLiveSportsEPGResponseXML,
+		"swedish full day":      swedishFullDayEPGResponseXML,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var r Response
+
+			if err := xml.Unmarshal(data, &r); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			b, err := xml.Marshal(&r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var want, got xmlNode
+
+			if err := xml.Unmarshal(data, &want); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := xml.Unmarshal(b, &got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if err := want.containedIn(got, want.XMLName.Local); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// unmodelled are the elements and attributes of the API that are not part of Response
+var unmodelled = map[string]bool{
+	"Video":       true,
+	"Image@Index": true,
+}
+
+// xmlNode is a generic XML element, used to compare marshalled output with a fixture document
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+// containedIn returns an error if an attribute, text or child element of n is missing in o
+func (n xmlNode) containedIn(o xmlNode, path string) error {
+	if n.XMLName.Local != o.XMLName.Local {
+		return fmt.Errorf("%s: element %s, want %s", path, o.XMLName.Local, n.XMLName.Local)
+	}
+
+	for _, a := range n.Attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || unmodelled[n.XMLName.Local+"@"+a.Name.Local] {
+			continue
+		}
+
+		if v, ok := o.attr(a.Name.Local); !ok {
+			return fmt.Errorf("%s: attribute %s is missing", path, a.Name.Local)
+		} else if v != a.Value {
+			return fmt.Errorf("%s: attribute %s = %q, want %q", path, a.Name.Local, v, a.Value)
+		}
+	}
+
+	if got, want := strings.TrimSpace(o.Content), strings.TrimSpace(n.Content); got != want {
+		return fmt.Errorf("%s: text = %q, want %q", path, got, want)
+	}
+
+	seen := map[string]int{}
+
+	for _, c := range n.Nodes {
+		if unmodelled[c.XMLName.Local] {
+			continue
+		}
+
+		i := seen[c.XMLName.Local]
+		seen[c.XMLName.Local]++
+
+		oc, ok := o.node(c.XMLName.Local, i)
+		if !ok {
+			return fmt.Errorf("%s: element %s[%d] is missing", path, c.XMLName.Local, i)
+		}
+
+		if err := c.containedIn(oc, fmt.Sprintf("%s/%s[%d]", path, c.XMLName.Local, i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// node returns the i:th child element with the given name
+func (n xmlNode) node(name string, i int) (xmlNode, bool) {
+	for _, c := range n.Nodes {
+		if c.XMLName.Local != name {
+			continue
+		}
+
+		if i == 0 {
+			return c, true
+		}
+
+		i--
+	}
+
+	return xmlNode{}, false
+}
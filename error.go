@@ -0,0 +1,95 @@
+package epg
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxErrorBodySize is the number of bytes of the response body kept in an APIError
+const maxErrorBodySize = 512
+
+// requestIDHeaders are the response headers checked (in order) for a request ID
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Correlation-Id"}
+
+// APIError is returned when the EPG API responds with an unexpected status code.
+//
+// It matches ErrNotFound, ErrBadRequest, ErrRateLimited or ErrServerError
+// (depending on the status code) when used with errors.Is, and any status code
+// other than 404 also matches ErrUnknown.
+type APIError struct {
+	StatusCode int
+	Path       string
+	Query      url.Values
+	Header     http.Header
+	Body       string
+	RequestID  string
+	Err        error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %d %s", e.Err, e.StatusCode, e.Path)
+
+	if e.RequestID != "" {
+		msg += " (request id " + e.RequestID + ")"
+	}
+
+	return msg
+}
+
+// Unwrap returns the sentinel error for the status code
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches target. All errors besides ErrNotFound
+// match ErrUnknown, since that is what the client used to return for them.
+func (e *APIError) Is(target error) bool {
+	return target == ErrUnknown && e.Err != ErrNotFound
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Err:        statusError(resp.StatusCode),
+	}
+
+	if resp.Request != nil && resp.Request.URL != nil {
+		e.Path = resp.Request.URL.Path
+		e.Query = resp.Request.URL.Query()
+	}
+
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+
+	var b strings.Builder
+
+	_, _ = io.Copy(&b, io.LimitReader(resp.Body, maxErrorBodySize))
+
+	e.Body = b.String()
+
+	return e
+}
+
+func statusError(code int) error {
+	switch {
+	case code == http.StatusNotFound:
+		return ErrNotFound
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= 500:
+		return ErrServerError
+	case code >= 400:
+		return ErrBadRequest
+	default:
+		return ErrUnknown
+	}
+}
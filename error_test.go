@@ -0,0 +1,78 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	for _, tt := range []struct {
+		status  int
+		err     error
+		unknown bool
+	}{
+		{http.StatusNotFound, ErrNotFound, false},
+		{http.StatusBadRequest, ErrBadRequest, true},
+		{http.StatusTooManyRequests, ErrRateLimited, true},
+		{http.StatusInternalServerError, ErrServerError, true},
+		{http.StatusServiceUnavailable, ErrServerError, true},
+		{http.StatusFound, ErrUnknown, true},
+	} {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Request-Id", "abc123")
+					w.WriteHeader(tt.status)
+					w.Write([]byte("<html>" + strings.Repeat("x", 2*maxErrorBodySize) + "</html>"))
+				}))
+			defer ts.Close()
+
+			_, err := NewClient(BaseURL(ts.URL)).Get(context.Background(), Sweden, Swedish, "2017-01-25")
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("errors.Is(%v, %v) = false, want true", err, tt.err)
+			}
+
+			if got, want := errors.Is(err, ErrUnknown), tt.unknown; got != want {
+				t.Fatalf("errors.Is(%v, ErrUnknown) = %v, want %v", err, got, want)
+			}
+
+			var apiErr *APIError
+
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("errors.As(%v, &apiErr) = false, want true", err)
+			}
+
+			if got, want := apiErr.StatusCode, tt.status; got != want {
+				t.Fatalf("apiErr.StatusCode = %d, want %d", got, want)
+			}
+
+			if got, want := apiErr.Path, "/epg/se/sv/2017-01-25"; got != want {
+				t.Fatalf("apiErr.Path = %q, want %q", got, want)
+			}
+
+			if got, want := apiErr.RequestID, "abc123"; got != want {
+				t.Fatalf("apiErr.RequestID = %q, want %q", got, want)
+			}
+
+			if got, want := len(apiErr.Body), maxErrorBodySize; got != want {
+				t.Fatalf("len(apiErr.Body) = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorError(t *testing.T) {
+	err := &APIError{StatusCode: 503, Path: "/epg/se/sv/2017-01-25", RequestID: "abc123", Err: ErrServerError}
+
+	if got, want := err.Error(), "server error: 503 /epg/se/sv/2017-01-25 (request id abc123)"; got != want {
+		t.Fatalf("err.Error() = %q, want %q", got, want)
+	}
+}
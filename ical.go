@@ -0,0 +1,195 @@
+package epg
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalTimeFormat is the format of local DATE-TIME values in iCalendar
+const icalTimeFormat = "20060102T150405"
+
+// icalUIDDomain is the domain part of the UID of events
+const icalUIDDomain = "epg.cmore.se"
+
+// icalLineLength is the maximum length of a content line in octets, excluding the line break
+const icalLineLength = 75
+
+// icalStockholm is the VTIMEZONE used for DTSTART and DTEND
+const icalStockholm = `BEGIN:VTIMEZONE
+TZID:Europe/Stockholm
+BEGIN:DAYLIGHT
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+TZNAME:CEST
+DTSTART:19700329T020000
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU
+END:DAYLIGHT
+BEGIN:STANDARD
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+TZNAME:CET
+DTSTART:19701025T030000
+RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU
+END:STANDARD
+END:VTIMEZONE`
+
+// ICalendarOptions are the options used when writing iCalendar
+type ICalendarOptions struct {
+	// Name is the name of the calendar, e.g. "Live sport on C More Hockey"
+	Name string
+
+	// Location is the location of all events, e.g. the channel title
+	Location string
+
+	// Timestamp is used for DTSTAMP (defaults to the current time)
+	Timestamp time.Time
+}
+
+// WriteICalendar writes the schedules as an iCalendar (RFC 5545) VCALENDAR
+// with one VEVENT per schedule to w. Schedules without a known start are skipped.
+func WriteICalendar(w io.Writer, schedules []Schedule, opts ICalendarOptions) error {
+	if opts.Timestamp.IsZero() {
+		opts.Timestamp = time.Now()
+	}
+
+	bw := bufio.NewWriter(w)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//TV4//epg//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+
+	if opts.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+icalEscape(opts.Name))
+	}
+
+	lines = append(lines, strings.Split(icalStockholm, "\n")...)
+
+	for _, s := range schedules {
+		lines = append(lines, icalEvent(s, opts)...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, l := range lines {
+		if _, err := bw.WriteString(icalFold(l)); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// icalEvent returns the content lines of the VEVENT for the schedule,
+// or nil if it has no known start
+func icalEvent(s Schedule, opts ICalendarOptions) []string {
+	start := s.Start()
+
+	if start.IsZero() {
+		return nil
+	}
+
+	p := s.Program
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + icalUID(s) + "@" + icalUIDDomain,
+		"DTSTAMP:" + opts.Timestamp.UTC().Format(icalTimeFormat) + "Z",
+		"DTSTART;TZID=Europe/Stockholm:" + start.In(Stockholm).Format(icalTimeFormat),
+	}
+
+	if stop := s.stop(); !stop.IsZero() {
+		lines = append(lines, "DTEND;TZID=Europe/Stockholm:"+stop.In(Stockholm).Format(icalTimeFormat))
+	}
+
+	summary := p.Title
+
+	if p.EpisodeTitle != "" && p.EpisodeTitle != p.Title {
+		summary += ": " + p.EpisodeTitle
+	}
+
+	lines = append(lines, "SUMMARY:"+icalEscape(summary))
+
+	if desc := p.synopsis(); desc != "" {
+		lines = append(lines, "DESCRIPTION:"+icalEscape(desc))
+	}
+
+	if opts.Location != "" {
+		lines = append(lines, "LOCATION:"+icalEscape(opts.Location))
+	}
+
+	var categories []string
+
+	for _, c := range []string{p.Genre, string(p.Category)} {
+		if c != "" {
+			categories = append(categories, icalEscape(c))
+		}
+	}
+
+	if len(categories) > 0 {
+		lines = append(lines, "CATEGORIES:"+strings.Join(categories, ","))
+	}
+
+	return append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+}
+
+// icalUID returns the schedule ID, or a hash of the start and title for schedules without ID
+func icalUID(s Schedule) string {
+	if s.ID != "" {
+		return s.ID
+	}
+
+	sum := sha1.Sum([]byte(s.Start().UTC().Format(icalTimeFormat) + "\x00" + s.Program.Title))
+
+	return hex.EncodeToString(sum[:])
+}
+
+var icalEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// icalEscape escapes a TEXT value
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// icalFold folds a content line into lines of at most 75 octets, without
+// splitting UTF-8 sequences, and terminates it with CRLF
+func icalFold(line string) string {
+	var (
+		b     strings.Builder
+		limit = icalLineLength
+	)
+
+	for len(line) > limit {
+		i := limit
+
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+
+		b.WriteString(line[:i])
+		b.WriteString("\r\n ")
+
+		// Continuation lines start with a space, which counts towards the limit
+		line, limit = line[i:], icalLineLength-1
+	}
+
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package epg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestWriteICalendar(t *testing.T) {
+	schedules := []Schedule{
+		{
+			ID:           "s1",
+			CalendarDate: Time{time.Date(2017, 7, 1, 19, 0, 0, 0, Stockholm)},
+			NextStart:    Time{time.Date(2017, 7, 1, 21, 30, 0, 0, Stockholm)},
+			Program: Program{
+				Title:        "Hockey; Final",
+				EpisodeTitle: "Frölunda, Skellefteå",
+				Genre:        "Sport",
+				SynopsisLong: "Line one\nLine two with a backslash \\",
+			},
+		},
+		{CalendarDate: Time{time.Date(2017, 1, 25, 20, 0, 0, 0, time.UTC)}, Program: Program{Title: "No ID", Duration: 30}},
+		{Program: Program{Title: "No start"}},
+	}
+
+	var buf bytes.Buffer
+
+	err := WriteICalendar(&buf, schedules, ICalendarOptions{
+		Name:      "Live sport",
+		Location:  "C More Hockey",
+		Timestamp: time.Date(2017, 1, 1, 12, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Live sport\r\n",
+		"TZID:Europe/Stockholm\r\n",
+		"UID:s1@epg.cmore.se\r\n",
+		"DTSTAMP:20170101T120000Z\r\n",
+		"DTSTART;TZID=Europe/Stockholm:20170701T190000\r\n",
+		"DTEND;TZID=Europe/Stockholm:20170701T213000\r\n",
+		`SUMMARY:Hockey\; Final: Frölunda\, Skellefteå` + "\r\n",
+		`DESCRIPTION:Line one\nLine two with a backslash \\` + "\r\n",
+		"LOCATION:C More Hockey\r\n",
+		"CATEGORIES:Sport\r\n",
+		"DTSTART;TZID=Europe/Stockholm:20170125T210000\r\n",
+		"DTEND;TZID=Europe/Stockholm:20170125T213000\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("iCalendar output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	if got, want := strings.Count(out, "BEGIN:VEVENT"), 2; got != want {
+		t.Fatalf("number of events = %d, want %d", got, want)
+	}
+
+	if strings.Contains(out, "No start") {
+		t.Fatalf("iCalendar output contains a schedule without start")
+	}
+
+	if got, want := icalUID(schedules[1]), icalUID(schedules[1]); got != want || len(got) != 40 {
+		t.Fatalf("icalUID = %q, want stable hash", got)
+	}
+}
+
+func TestICalFold(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("å", 100)
+
+	folded := icalFold(line)
+
+	if !strings.HasSuffix(folded, "\r\n") {
+		t.Fatalf("folded line does not end with CRLF")
+	}
+
+	parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+
+	if got, want := len(parts), 3; got != want {
+		t.Fatalf("len(parts) = %d, want %d", got, want)
+	}
+
+	var unfolded string
+
+	for i, p := range parts {
+		if len(p) > icalLineLength {
+			t.Fatalf("len(parts[%d]) = %d, want at most %d", i, len(p), icalLineLength)
+		}
+
+		if i > 0 {
+			if !strings.HasPrefix(p, " ") {
+				t.Fatalf("parts[%d] does not start with a space", i)
+			}
+
+			p = p[1:]
+		}
+
+		if !utf8.ValidString(p) {
+			t.Fatalf("parts[%d] splits a UTF-8 sequence", i)
+		}
+
+		unfolded += p
+	}
+
+	if unfolded != line {
+		t.Fatalf("unfolded = %q, want %q", unfolded, line)
+	}
+
+	if got, want := icalFold("SHORT"), "SHORT\r\n"; got != want {
+		t.Fatalf("icalFold(%q) = %q, want %q", "SHORT", got, want)
+	}
+}
+
+func TestICalEscape(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"a;b,c", `a\;b\,c`},
+		{`back\slash`, `back\\slash`},
+		{"one\r\ntwo\nthree", `one\ntwo\nthree`},
+	} {
+		if got := icalEscape(tt.in); got != tt.want {
+			t.Fatalf("icalEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package epg
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metric names used when reporting to Metrics
+const (
+	// MetricRequestDuration is the duration of each HTTP request, labeled by status
+	MetricRequestDuration = "epg_request_duration"
+
+	// MetricDecodeDuration is the time spent decoding a response
+	MetricDecodeDuration = "epg_decode_duration"
+
+	// MetricBytesRead is the number of response body bytes read from the API
+	MetricBytesRead = "epg_bytes_read"
+
+	// MetricRetries is the number of retried requests
+	MetricRetries = "epg_retries"
+
+	// MetricDays is the number of days in decoded responses
+	MetricDays = "epg_days"
+
+	// MetricChannels is the number of channels in decoded responses
+	MetricChannels = "epg_channels"
+
+	// MetricSchedules is the number of schedules in decoded responses
+	MetricSchedules = "epg_schedules"
+)
+
+// Labels are the labels (dimensions) of a metric
+type Labels map[string]string
+
+// Tracer starts spans, typically an adapter for OpenTelemetry or similar
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// Metrics records measurements, typically an adapter for Prometheus or similar
+type Metrics interface {
+	ObserveDuration(name string, d time.Duration, labels Labels)
+	AddCount(name string, n int64, labels Labels)
+}
+
+// Instrument makes the *client report spans to the tracer and measurements to
+// the metrics. Either of them may be nil.
+func Instrument(tracer Tracer, metrics Metrics) func(*Client) {
+	return func(c *Client) {
+		c.tracer = tracer
+		c.metrics = metrics
+	}
+}
+
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, nopSpan{}
+	}
+
+	return c.tracer.StartSpan(ctx, name)
+}
+
+func (c *Client) observeDuration(name string, d time.Duration, labels Labels) {
+	if c.metrics != nil {
+		c.metrics.ObserveDuration(name, d, labels)
+	}
+}
+
+func (c *Client) addCount(name string, n int64, labels Labels) {
+	if c.metrics != nil {
+		c.metrics.AddCount(name, n, labels)
+	}
+}
+
+// observeRequest records the duration and outcome of an HTTP request
+func (c *Client) observeRequest(ex *Exchange, d time.Duration) {
+	status := "error"
+
+	if ex.Response != nil {
+		status = strconv.Itoa(ex.Response.StatusCode)
+	}
+
+	c.observeDuration(MetricRequestDuration, d, Labels{"status": status})
+
+	if ex.Attempt > 1 {
+		c.addCount(MetricRetries, 1, nil)
+	}
+}
+
+// observeResponse records the size of a decoded response
+func (c *Client) observeResponse(span Span, r *Response) {
+	var channels, schedules int
+
+	for _, d := range r.Days {
+		channels += len(d.Channels)
+
+		for _, ch := range d.Channels {
+			schedules += len(ch.Schedules)
+		}
+	}
+
+	c.addCount(MetricDays, int64(len(r.Days)), nil)
+	c.addCount(MetricChannels, int64(channels), nil)
+	c.addCount(MetricSchedules, int64(schedules), nil)
+
+	span.SetAttribute("epg.days", len(r.Days))
+	span.SetAttribute("epg.channels", channels)
+	span.SetAttribute("epg.schedules", schedules)
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+func (nopSpan) End(error)                        {}
+
+// countingReadCloser counts the bytes read through it
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (cr *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := cr.ReadCloser.Read(p)
+	cr.n += int64(n)
+
+	return n, err
+}
+
+// MemoryRecorder is an in-memory Tracer and Metrics, useful in tests
+type MemoryRecorder struct {
+	mu           sync.Mutex
+	spans        []*RecordedSpan
+	measurements []Measurement
+}
+
+// RecordedSpan is a span recorded by a MemoryRecorder
+type RecordedSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Err        error
+	Ended      bool
+}
+
+// Measurement is a measurement recorded by a MemoryRecorder.
+// Durations are recorded in seconds.
+type Measurement struct {
+	Name   string
+	Value  float64
+	Labels Labels
+}
+
+// StartSpan implements Tracer
+func (mr *MemoryRecorder) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	s := &RecordedSpan{Name: name, Attributes: map[string]interface{}{}}
+
+	mr.spans = append(mr.spans, s)
+
+	return ctx, &memorySpan{mr, s}
+}
+
+// ObserveDuration implements Metrics
+func (mr *MemoryRecorder) ObserveDuration(name string, d time.Duration, labels Labels) {
+	mr.record(Measurement{name, d.Seconds(), labels})
+}
+
+// AddCount implements Metrics
+func (mr *MemoryRecorder) AddCount(name string, n int64, labels Labels) {
+	mr.record(Measurement{name, float64(n), labels})
+}
+
+// Spans returns copies of the recorded spans
+func (mr *MemoryRecorder) Spans() []RecordedSpan {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	spans := make([]RecordedSpan, 0, len(mr.spans))
+
+	for _, s := range mr.spans {
+		c := *s
+
+		c.Attributes = make(map[string]interface{}, len(s.Attributes))
+
+		for k, v := range s.Attributes {
+			c.Attributes[k] = v
+		}
+
+		spans = append(spans, c)
+	}
+
+	return spans
+}
+
+// Measurements returns the recorded measurements with the given name
+func (mr *MemoryRecorder) Measurements(name string) []Measurement {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	var measurements []Measurement
+
+	for _, m := range mr.measurements {
+		if m.Name == name {
+			measurements = append(measurements, m)
+		}
+	}
+
+	return measurements
+}
+
+// Sum returns the sum of the values of the measurements with the given name
+func (mr *MemoryRecorder) Sum(name string) float64 {
+	var sum float64
+
+	for _, m := range mr.Measurements(name) {
+		sum += m.Value
+	}
+
+	return sum
+}
+
+func (mr *MemoryRecorder) record(m Measurement) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.measurements = append(mr.measurements, m)
+}
+
+type memorySpan struct {
+	mr *MemoryRecorder
+	s  *RecordedSpan
+}
+
+func (ms *memorySpan) SetAttribute(key string, value interface{}) {
+	ms.mr.mu.Lock()
+	defer ms.mr.mu.Unlock()
+
+	ms.s.Attributes[key] = value
+}
+
+func (ms *memorySpan) End(err error) {
+	ms.mr.mu.Lock()
+	defer ms.mr.mu.Unlock()
+
+	ms.s.Err = err
+	ms.s.Ended = true
+}
@@ -0,0 +1,105 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestInstrument(t *testing.T) {
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	mr := &MemoryRecorder{}
+
+	Instrument(mr, mr)(c)
+
+	if _, err := c.GetChannel(context.Background(), Finland, Finnish, "2017-01-27", "2017-01-27", CanalHD); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := mr.Spans()
+
+	if got, want := len(spans), 1; got != want {
+		t.Fatalf("len(spans) = %d, want %d", got, want)
+	}
+
+	s := spans[0]
+
+	if !s.Ended || s.Err != nil {
+		t.Fatalf("span Ended = %v, Err = %v, want ended without error", s.Ended, s.Err)
+	}
+
+	for k, want := range map[string]interface{}{
+		"epg.path":         "/epg/fi/fi/2017-01-27/2017-01-27/12",
+		"epg.attempts":     1,
+		"http.status_code": 200,
+		"epg.days":         1,
+		"epg.channels":     1,
+	} {
+		if got := s.Attributes[k]; got != want {
+			t.Fatalf("s.Attributes[%q] = %v, want %v", k, got, want)
+		}
+	}
+
+	requests := mr.Measurements(MetricRequestDuration)
+
+	if got, want := len(requests), 1; got != want {
+		t.Fatalf("len(requests) = %d, want %d", got, want)
+	}
+
+	if got, want := requests[0].Labels["status"], "200"; got != want {
+		t.Fatalf(`requests[0].Labels["status"] = %q, want %q`, got, want)
+	}
+
+	if got, want := mr.Sum(MetricBytesRead), float64(len(finnishChannel12ResponseXML)); got != want {
+		t.Fatalf("bytes read = %v, want %v", got, want)
+	}
+
+	if got, want := len(mr.Measurements(MetricDecodeDuration)), 1; got != want {
+		t.Fatalf("len(decode durations) = %d, want %d", got, want)
+	}
+
+	if got, want := mr.Sum(MetricSchedules), float64(s.Attributes["epg.schedules"].(int)); got == 0 || got != want {
+		t.Fatalf("schedules = %v, want %v (non-zero)", got, want)
+	}
+}
+
+func TestInstrumentRetries(t *testing.T) {
+	ts, _ := testFailingServer(5, http.StatusServiceUnavailable, "0")
+	defer ts.Close()
+
+	mr := &MemoryRecorder{}
+
+	c := NewClient(
+		BaseURL(ts.URL),
+		Retry(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}),
+		Instrument(mr, mr),
+	)
+
+	_, err := c.Get(context.Background(), Sweden, Swedish, "2017-01-25")
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("err = %v, want ErrServerError", err)
+	}
+
+	if got, want := mr.Sum(MetricRetries), float64(2); got != want {
+		t.Fatalf("retries = %v, want %v", got, want)
+	}
+
+	if got, want := mr.Spans()[0].Err, err; got != want {
+		t.Fatalf("span error = %v, want %v", got, want)
+	}
+}
+
+func TestInstrumentNil(t *testing.T) {
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	Instrument(nil, nil)(c)
+
+	if _, err := c.Get(context.Background(), Sweden, Swedish, "2017-01-25"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
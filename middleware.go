@@ -0,0 +1,88 @@
+package epg
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Exchange is the state of a single call to the EPG API, passed to Hooks
+type Exchange struct {
+	// Path and Query of the request
+	Path  string
+	Query url.Values
+
+	// Attempt is the (1-based) attempt, incremented on each retry
+	Attempt int
+
+	// Request is the request for the current attempt
+	Request *http.Request
+
+	// Response is the response for the current attempt, or nil if Err is set
+	Response *http.Response
+
+	// Err is the error returned by the HTTP client for the current attempt
+	Err error
+
+	// Result is the decoded response
+	Result *Response
+}
+
+// Hooks are functions called around the lifecycle of a call to the EPG API.
+// Any of the hooks may be nil, and returning an error aborts the call.
+type Hooks struct {
+	// BeforeSend is called before each attempt is sent, and may modify the Request
+	BeforeSend func(*Exchange) error
+
+	// AfterReceive is called after each attempt, with either a Response or an Err
+	AfterReceive func(*Exchange) error
+
+	// AfterDecode is called once the Result has been decoded (not called by StreamPeriod)
+	AfterDecode func(*Exchange) error
+}
+
+// Middleware adds the provided hooks to the *client.
+//
+// BeforeSend hooks are called in the order they were added, while
+// AfterReceive and AfterDecode hooks are called in reverse order, so
+// that the first hooks added wrap all the others.
+func Middleware(hooks ...Hooks) func(*Client) {
+	return func(c *Client) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+func (c *Client) beforeSend(ex *Exchange) error {
+	for _, h := range c.hooks {
+		if h.BeforeSend != nil {
+			if err := h.BeforeSend(ex); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) afterReceive(ex *Exchange) error {
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		if h := c.hooks[i]; h.AfterReceive != nil {
+			if err := h.AfterReceive(ex); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) afterDecode(ex *Exchange) error {
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		if h := c.hooks[i]; h.AfterDecode != nil {
+			if err := h.AfterDecode(ex); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Write(finnishChannel12ResponseXML)
+		}))
+	defer ts.Close()
+
+	var calls []string
+
+	record := func(name string) Hooks {
+		return Hooks{
+			BeforeSend: func(ex *Exchange) error {
+				calls = append(calls, name+" before send")
+				return nil
+			},
+			AfterReceive: func(ex *Exchange) error {
+				calls = append(calls, name+" after receive")
+				return nil
+			},
+			AfterDecode: func(ex *Exchange) error {
+				calls = append(calls, name+" after decode")
+				return nil
+			},
+		}
+	}
+
+	auth := Hooks{
+		BeforeSend: func(ex *Exchange) error {
+			ex.Request.Header.Set("Authorization", "Bearer secret")
+			return nil
+		},
+		AfterDecode: func(ex *Exchange) error {
+			if got, want := ex.Path, "/epg/fi/fi/2017-01-27/2017-01-27/12"; got != want {
+				t.Fatalf("ex.Path = %q, want %q", got, want)
+			}
+
+			if got, want := ex.Response.StatusCode, http.StatusOK; got != want {
+				t.Fatalf("ex.Response.StatusCode = %d, want %d", got, want)
+			}
+
+			if got, want := len(ex.Result.Days), 1; got != want {
+				t.Fatalf("len(ex.Result.Days) = %d, want %d", got, want)
+			}
+
+			return nil
+		},
+	}
+
+	c := NewClient(BaseURL(ts.URL), Middleware(record("outer"), auth), Middleware(record("inner")))
+
+	if _, err := c.GetChannel(context.Background(), Finland, Finnish, "2017-01-27", "2017-01-27", CanalHD); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"outer before send",
+		"inner before send",
+		"inner after receive",
+		"outer after receive",
+		"inner after decode",
+		"outer after decode",
+	}
+
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestMiddlewareRetries(t *testing.T) {
+	ts, _ := testFailingServer(1, http.StatusBadGateway, "0")
+	defer ts.Close()
+
+	var attempts []int
+
+	c := NewClient(
+		BaseURL(ts.URL),
+		Retry(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}),
+		Middleware(Hooks{
+			AfterReceive: func(ex *Exchange) error {
+				attempts = append(attempts, ex.Attempt)
+				return nil
+			},
+		}),
+	)
+
+	if _, err := c.Get(context.Background(), Sweden, Swedish, "2017-01-25"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := attempts, []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("attempts = %v, want %v", got, want)
+	}
+}
+
+func TestMiddlewareAbort(t *testing.T) {
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	errAbort := errors.New("abort")
+
+	for name, hooks := range map[string]Hooks{
+		"before send":   {BeforeSend: func(*Exchange) error { return errAbort }},
+		"after receive": {AfterReceive: func(*Exchange) error { return errAbort }},
+		"after decode":  {AfterDecode: func(*Exchange) error { return errAbort }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			Middleware(hooks)(c)
+			defer func() { c.hooks = nil }()
+
+			if _, err := c.Get(context.Background(), Sweden, Swedish, "2017-01-25"); err != errAbort {
+				t.Fatalf("err = %v, want %v", err, errAbort)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package epg
+
+import (
+	"sort"
+	"time"
+)
+
+// Airing is a schedule airing on a channel. The Channel has no Schedules.
+type Airing struct {
+	Channel  Channel
+	Schedule Schedule
+}
+
+// ScheduleAt returns the schedule airing on the channel at t.
+// The schedules of the channel must be sorted by start time.
+// Returns empty Schedule if not found
+func (c Channel) ScheduleAt(t time.Time) Schedule {
+	if i := scheduleIndex(c.Schedules, t); i >= 0 {
+		return c.Schedules[i]
+	}
+
+	return Schedule{}
+}
+
+// OnAt returns what is airing at t on each channel in the response,
+// searching across all days, in the order the channels first appear
+func (r *Response) OnAt(t time.Time) []Airing {
+	var airings []Airing
+
+	for _, tl := range r.timelines(nil) {
+		if i := scheduleIndex(tl.schedules, t); i >= 0 {
+			airings = append(airings, Airing{Channel: tl.channel, Schedule: tl.schedules[i]})
+		}
+	}
+
+	return airings
+}
+
+// NowNext returns the schedule airing at t on the channel with the given id,
+// and (at most) n schedules following it, searching across all days.
+// Returns empty Schedule if nothing is airing at t
+func (r *Response) NowNext(channelID string, t time.Time, n int) (Schedule, []Schedule) {
+	_, schedules := r.channelSchedules(channelID)
+
+	var now Schedule
+
+	i := scheduleIndex(schedules, t)
+
+	if i >= 0 {
+		now = schedules[i]
+	}
+
+	// Schedules starting after t follow, whether something is airing or not
+	j := sort.Search(len(schedules), func(k int) bool {
+		return schedules[k].Start().After(t)
+	})
+
+	var next []Schedule
+
+	for ; j < len(schedules) && len(next) < n; j++ {
+		next = append(next, schedules[j])
+	}
+
+	return now, next
+}
+
+// Schedules returns the schedules of all days matching fn, sorted by start
+// time and without duplicates, e.g. all live sport on a channel
+func (r *Response) Schedules(fn func(Channel, Schedule) bool) []Schedule {
+	var (
+		schedules []Schedule
+		seen      = map[string]bool{}
+	)
+
+	for _, d := range r.Days {
+		for _, c := range d.Channels {
+			for _, s := range c.Schedules {
+				if s.ID != "" && seen[s.ID] {
+					continue
+				}
+
+				if fn(c, s) {
+					seen[s.ID] = true
+					schedules = append(schedules, s)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return schedules[i].Start().Before(schedules[j].Start())
+	})
+
+	return schedules
+}
+
+// timeline is a channel (without schedules) and its schedules for all days
+type timeline struct {
+	channel   Channel
+	schedules []Schedule
+}
+
+// timelines returns the timelines of the channels with an ID matching keep,
+// or of all channels if keep is nil, in the order the channels first appear.
+// The schedules are sorted by start time, without duplicates
+func (r *Response) timelines(keep func(id string) bool) []timeline {
+	var (
+		timelines []timeline
+		index     = map[string]int{}
+		seen      = map[string]bool{} // channel ID and schedule ID
+	)
+
+	for _, d := range r.Days {
+		for _, c := range d.Channels {
+			if keep != nil && !keep(c.ID) {
+				continue
+			}
+
+			i, ok := index[c.ID]
+			if !ok {
+				i = len(timelines)
+				index[c.ID] = i
+
+				channel := c
+				channel.Schedules = nil
+
+				timelines = append(timelines, timeline{channel: channel})
+			}
+
+			for _, s := range c.Schedules {
+				key := c.ID + "/" + s.ID
+
+				if s.ID != "" && seen[key] {
+					continue
+				}
+
+				seen[key] = true
+				timelines[i].schedules = append(timelines[i].schedules, s)
+			}
+		}
+	}
+
+	for _, tl := range timelines {
+		schedules := tl.schedules
+
+		sort.SliceStable(schedules, func(i, j int) bool {
+			return schedules[i].Start().Before(schedules[j].Start())
+		})
+	}
+
+	return timelines
+}
+
+// channelSchedules returns the channel with the given id (without schedules),
+// and its schedules for all days sorted by start time, without duplicates
+func (r *Response) channelSchedules(id string) (Channel, []Schedule) {
+	timelines := r.timelines(func(cid string) bool { return cid == id })
+
+	if len(timelines) == 0 {
+		return Channel{}, nil
+	}
+
+	return timelines[0].channel, timelines[0].schedules
+}
+
+// scheduleIndex returns the index of the schedule airing at t in schedules
+// sorted by start time, or -1 if nothing is airing at t
+func scheduleIndex(schedules []Schedule, t time.Time) int {
+	i := sort.Search(len(schedules), func(i int) bool {
+		return schedules[i].Start().After(t)
+	}) - 1
+
+	if i < 0 || schedules[i].Start().IsZero() {
+		return -1
+	}
+
+	end := schedules[i].stop()
+
+	// A schedule without a known end is considered airing until the next one starts
+	if !end.IsZero() && !t.Before(end) {
+		return -1
+	}
+
+	return i
+}
@@ -0,0 +1,191 @@
+package epg
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChannelScheduleAt(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(swedishFullDayEPGResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tv4 := r.Day().Channel(TV4)
+
+	for _, tt := range []struct {
+		time  time.Time
+		title string
+	}{
+		{time.Date(2017, 1, 25, 19, 0, 0, 0, Stockholm), "TV4Nyheterna"},
+		{time.Date(2017, 1, 25, 19, 5, 0, 0, Stockholm), "TV4Nyheterna"},
+		{time.Date(2017, 1, 24, 12, 0, 0, 0, Stockholm), ""},
+		{time.Date(2017, 1, 27, 12, 0, 0, 0, Stockholm), ""},
+	} {
+		if got := tv4.ScheduleAt(tt.time).Program.Title; got != tt.title {
+			t.Fatalf("tv4.ScheduleAt(%v).Program.Title = %q, want %q", tt.time, got, tt.title)
+		}
+	}
+}
+
+func TestResponseOnAt(t *testing.T) {
+	r := testMidnightResponse()
+
+	airings := r.OnAt(time.Date(2017, 1, 26, 0, 30, 0, 0, Stockholm))
+
+	if got, want := len(airings), 2; got != want {
+		t.Fatalf("len(airings) = %d, want %d", got, want)
+	}
+
+	for i, want := range []struct {
+		channelID  string
+		scheduleID string
+	}{
+		{"1", "late"},
+		{"2", "overnight"},
+	} {
+		if got := airings[i].Channel.ID; got != want.channelID {
+			t.Fatalf("airings[%d].Channel.ID = %q, want %q", i, got, want.channelID)
+		}
+
+		if got := airings[i].Schedule.ID; got != want.scheduleID {
+			t.Fatalf("airings[%d].Schedule.ID = %q, want %q", i, got, want.scheduleID)
+		}
+
+		if airings[i].Channel.Schedules != nil {
+			t.Fatalf("airings[%d].Channel.Schedules != nil", i)
+		}
+	}
+}
+
+func TestResponseNowNext(t *testing.T) {
+	r := testMidnightResponse()
+
+	for _, tt := range []struct {
+		name string
+		time time.Time
+		n    int
+		now  string
+		next []string
+	}{
+		{"across midnight", time.Date(2017, 1, 26, 0, 30, 0, 0, Stockholm), 2, "late", []string{"night", "morning"}},
+		{"limited", time.Date(2017, 1, 26, 0, 30, 0, 0, Stockholm), 1, "late", []string{"night"}},
+		{"gap", time.Date(2017, 1, 26, 3, 0, 0, 0, Stockholm), 5, "", []string{"morning"}},
+		{"before", time.Date(2017, 1, 25, 12, 0, 0, 0, Stockholm), 1, "", []string{"evening"}},
+		{"after", time.Date(2017, 1, 27, 12, 0, 0, 0, Stockholm), 1, "", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			now, next := r.NowNext("1", tt.time, tt.n)
+
+			if got := now.ID; got != tt.now {
+				t.Fatalf("now.ID = %q, want %q", got, tt.now)
+			}
+
+			if got, want := len(next), len(tt.next); got != want {
+				t.Fatalf("len(next) = %d, want %d", got, want)
+			}
+
+			for i := range next {
+				if got, want := next[i].ID, tt.next[i]; got != want {
+					t.Fatalf("next[%d].ID = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// testMidnightResponse returns a response with two broadcast days, where
+// schedules cross midnight and are repeated in both days
+func testMidnightResponse() *Response {
+	at := func(day, hour int) Time {
+		return Time{time.Date(2017, 1, day, hour, 0, 0, 0, Stockholm)}
+	}
+
+	late := Schedule{ID: "late", CalendarDate: at(25, 23), NextStart: at(26, 1)}
+
+	return &Response{
+		Days: []Day{
+			{
+				BroadcastDate: at(25, 0),
+				Channels: []Channel{
+					{ID: "1", Schedules: []Schedule{
+						{ID: "evening", CalendarDate: at(25, 20), NextStart: at(25, 23)},
+						late,
+					}},
+					{ID: "2", Schedules: []Schedule{
+						{ID: "overnight", CalendarDate: at(25, 22), NextStart: at(26, 6)},
+					}},
+				},
+			},
+			{
+				BroadcastDate: at(26, 0),
+				Channels: []Channel{
+					{ID: "1", Schedules: []Schedule{
+						late,
+						{ID: "night", CalendarDate: at(26, 1), NextStart: at(26, 2)},
+						{ID: "morning", CalendarDate: at(26, 6), NextStart: at(26, 9)},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestResponseSchedules(t *testing.T) {
+	r := testMidnightResponse()
+
+	schedules := r.Schedules(func(c Channel, s Schedule) bool {
+		return c.ID == "1" && s.ID != "evening"
+	})
+
+	var ids []string
+
+	for _, s := range schedules {
+		ids = append(ids, s.ID)
+	}
+
+	if got, want := strings.Join(ids, ","), "late,night,morning"; got != want {
+		t.Fatalf("schedule IDs = %q, want %q", got, want)
+	}
+}
+
+func TestResponseTimelines(t *testing.T) {
+	r := testMidnightResponse()
+
+	var got []string
+
+	for _, tl := range r.timelines(nil) {
+		var ids []string
+
+		for _, s := range tl.schedules {
+			ids = append(ids, s.ID)
+		}
+
+		got = append(got, tl.channel.ID+":"+strings.Join(ids, ","))
+
+		if tl.channel.Schedules != nil {
+			t.Fatalf("channel %s has schedules", tl.channel.ID)
+		}
+	}
+
+	if got, want := strings.Join(got, " "), "1:evening,late,night,morning 2:overnight"; got != want {
+		t.Fatalf("timelines = %q, want %q", got, want)
+	}
+}
+
+func BenchmarkResponseOnAt(b *testing.B) {
+	var r Response
+
+	if err := xml.Unmarshal(swedishFullDayEPGResponseXML, &r); err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	t := time.Date(2017, 1, 25, 20, 0, 0, 0, Stockholm)
+
+	for i := 0; i < b.N; i++ {
+		r.OnAt(t)
+	}
+}
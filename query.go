@@ -0,0 +1,152 @@
+package epg
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// Filter is a value for the filter query attribute
+type Filter string
+
+const (
+	// FilterPrimetimeMovies only includes movies broadcast in prime time
+	FilterPrimetimeMovies Filter = "primetimemovies"
+
+	// FilterLiveSports only includes live sports
+	FilterLiveSports Filter = "livesports"
+)
+
+// Genre is a value for the genre query attribute
+type Genre string
+
+const (
+	// GenreChildren is the genre children
+	GenreChildren Genre = "children"
+
+	// GenreComedy is the genre comedy
+	GenreComedy Genre = "comedy"
+
+	// GenreDocumentary is the genre documentary
+	GenreDocumentary Genre = "documentary"
+
+	// GenreDrama is the genre drama
+	GenreDrama Genre = "drama"
+
+	// GenreFamily is the genre family
+	GenreFamily Genre = "family"
+
+	// GenreIceHockey is the genre ice hockey
+	GenreIceHockey Genre = "icehockey"
+
+	// GenreSport is the genre sport
+	GenreSport Genre = "sport"
+)
+
+// queryKeys are the query attributes known to be supported by the EPG API
+var queryKeys = map[string]bool{
+	"filter": true,
+	"genre":  true,
+}
+
+// Query is a typed set of query attributes. Use Values to pass it to the
+// methods of the Client:
+//
+//	q := epg.Query{}.Filter(epg.FilterPrimetimeMovies).Genre(epg.GenreDrama)
+//
+//	r, err := c.Get(ctx, epg.Sweden, epg.Swedish, date, q.Values())
+//
+// Filter, Genre and Merge return a new query and leave the receiver unchanged,
+// so a base query can be reused. The Client sends queries as they are, call
+// Validate to check for attributes the EPG API does not support
+type Query url.Values
+
+// Filter returns the query with the provided filters added
+func (q Query) Filter(filters ...Filter) Query {
+	q = q.clone()
+
+	for _, f := range filters {
+		q = q.add("filter", string(f))
+	}
+
+	return q
+}
+
+// Genre returns the query with the provided genres added
+func (q Query) Genre(genres ...Genre) Query {
+	q = q.clone()
+
+	for _, g := range genres {
+		q = q.add("genre", string(g))
+	}
+
+	return q
+}
+
+// Merge returns the query with all values of the provided attribute sets added
+func (q Query) Merge(attributes ...url.Values) Query {
+	q = q.clone()
+
+	for _, a := range attributes {
+		for k, vs := range a {
+			for _, v := range vs {
+				q = q.add(k, v)
+			}
+		}
+	}
+
+	return q
+}
+
+// Validate returns an error wrapping ErrInvalidQuery if the query
+// contains attributes not known to be supported by the EPG API
+func (q Query) Validate() error {
+	var unknown []string
+
+	for k := range q {
+		if !queryKeys[k] {
+			unknown = append(unknown, k)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return fmt.Errorf("%w: unknown attributes %q", ErrInvalidQuery, unknown)
+	}
+
+	return nil
+}
+
+// Encode encodes the query in URL encoded form, sorted by key
+func (q Query) Encode() string {
+	return url.Values(q).Encode()
+}
+
+// Values returns the query as url.Values
+func (q Query) Values() url.Values {
+	return url.Values(q)
+}
+
+// clone returns a copy of the query that can be modified by add
+func (q Query) clone() Query {
+	return Query(cloneValues(url.Values(q)))
+}
+
+// add adds the value to key, unless it is already present. It modifies q,
+// which must not be shared, see clone
+func (q Query) add(key, value string) Query {
+	if q == nil {
+		q = Query{}
+	}
+
+	for _, v := range q[key] {
+		if v == value {
+			return q
+		}
+	}
+
+	q[key] = append(q[key], value)
+
+	return q
+}
@@ -0,0 +1,96 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"empty", Query{}, ""},
+		{"nil", Query(nil).Filter(FilterLiveSports), "filter=livesports"},
+		{"filter", Query{}.Filter(FilterPrimetimeMovies), "filter=primetimemovies"},
+		{"genre", Query{}.Genre(GenreDrama, GenreComedy), "genre=drama&genre=comedy"},
+		{"both", Query{}.Genre(GenreDrama).Filter(FilterPrimetimeMovies), "filter=primetimemovies&genre=drama"},
+		{"duplicate", Query{}.Genre(GenreDrama).Genre(GenreDrama), "genre=drama"},
+		{"merge", Query{}.Merge(
+			url.Values{"genre": {"drama"}},
+			url.Values{"filter": {"livesports"}, "genre": {"drama", "sport"}},
+		), "filter=livesports&genre=drama&genre=sport"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Encode(); got != tt.want {
+				t.Fatalf("tt.query.Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryReuse(t *testing.T) {
+	base := Query{}.Genre(GenreDrama)
+
+	a := base.Filter(FilterLiveSports)
+	b := base.Filter(FilterPrimetimeMovies)
+	c := base.Merge(url.Values{"genre": {"comedy"}})
+
+	for _, tt := range []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"base", base, "genre=drama"},
+		{"a", a, "filter=livesports&genre=drama"},
+		{"b", b, "filter=primetimemovies&genre=drama"},
+		{"c", c, "genre=drama&genre=comedy"},
+	} {
+		if got := tt.query.Encode(); got != tt.want {
+			t.Fatalf("%s.Encode() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestQueryValidate(t *testing.T) {
+	if err := (Query{}.Filter(FilterLiveSports).Genre(GenreSport)).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := Query{}.Merge(url.Values{"genere": {"drama"}, "fliter": {"livesports"}}).Validate()
+
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("err = %v, want ErrInvalidQuery", err)
+	}
+
+	if got, want := err.Error(), `invalid query: unknown attributes ["fliter" "genere"]`; got != want {
+		t.Fatalf("err.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestClientMergesAttributes(t *testing.T) {
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	// The query of both attribute sets is needed to match the live sports response
+	r, err := c.GetChannelGroup(
+		context.Background(),
+		Sweden,
+		Swedish,
+		Date(2017, 1, 27),
+		Date(2017, 1, 27),
+		"27",
+		url.Values{},
+		Query{}.Filter(FilterLiveSports).Values(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %#v", err)
+	}
+
+	if got, want := len(r.Day().Channels), 9; got != want {
+		t.Fatalf("len(r.Day().Channels) = %d, want %d", got, want)
+	}
+}
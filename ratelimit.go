@@ -0,0 +1,102 @@
+package epg
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the *client to rps requests per second, allowing bursts of
+// up to burst requests. Every attempt (including retries) consumes a token.
+//
+// The token bucket is created when RateLimit is called, so passing the same
+// option to several calls of NewClient makes those clients share the limit:
+//
+//	limit := epg.RateLimit(5, 10)
+//
+//	se := epg.NewClient(limit)
+//	dk := epg.NewClient(limit, epg.Retry(epg.DefaultRetryPolicy))
+func RateLimit(rps float64, burst int) func(*Client) {
+	l := newLimiter(rps, burst)
+
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// limiter is a token bucket safe for concurrent use
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rps float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available, or returns an error if ctx is done
+// (or would be done) before that
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	now := time.Now()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	l.last = now
+
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	// Reserve a token, possibly going into debt that later callers have to wait for
+	l.tokens--
+
+	var delay time.Duration
+
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+
+		return context.DeadlineExceeded
+	}
+
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
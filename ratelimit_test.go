@@ -0,0 +1,86 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	ts, calls := testFailingServer(0, http.StatusOK, "")
+	defer ts.Close()
+
+	limit := RateLimit(100, 2)
+
+	a := NewClient(BaseURL(ts.URL), limit)
+	b := NewClient(BaseURL(ts.URL), limit)
+
+	if a.limiter != b.limiter {
+		t.Fatalf("a.limiter != b.limiter, want shared limiter")
+	}
+
+	start := time.Now()
+
+	for _, c := range []*Client{a, b, a, b} {
+		if _, err := c.Get(context.Background(), Sweden, Swedish, "2017-01-25"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Two requests fit in the burst, the other two have to wait 10ms each
+	if got, want := time.Since(start), 15*time.Millisecond; got < want {
+		t.Fatalf("time.Since(start) = %s, want at least %s", got, want)
+	}
+
+	if got, want := atomic.LoadInt32(calls), int32(4); got != want {
+		t.Fatalf("calls = %d, want %d", got, want)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		var l *limiter
+
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		l := newLimiter(1, 1)
+
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("l.wait(ctx) = %v, want context.DeadlineExceeded", err)
+		}
+
+		if l.tokens < -0.01 {
+			t.Fatalf("l.tokens = %f, want the reserved token to be returned", l.tokens)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		l := newLimiter(0.5, 1)
+		l.tokens = 0
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			cancel()
+		}()
+
+		if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("l.wait(ctx) = %v, want context.Canceled", err)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package epg
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestRatingMinimumAge(t *testing.T) {
+	for _, tt := range []struct {
+		rating  Rating
+		country Country
+		age     int
+		ok      bool
+	}{
+		{RatingGreen, Sweden, 0, true},
+		{RatingTurquoise, Norway, 6, true},
+		{RatingBlue, Finland, 12, true},
+		{RatingOrange, Denmark, 15, true},
+		{RatingOrange, Finland, 16, true},
+		{RatingBlue, Country("xx"), 11, true},
+		{RatingUnrated, Sweden, 0, false},
+		{Rating("PURPLE"), Sweden, 0, false},
+	} {
+		age, ok := tt.rating.MinimumAge(tt.country)
+
+		if age != tt.age || ok != tt.ok {
+			t.Fatalf("%q.MinimumAge(%q) = %d, %v, want %d, %v", tt.rating, tt.country, age, ok, tt.age, tt.ok)
+		}
+	}
+}
+
+func TestRatingAllowedFor(t *testing.T) {
+	for _, tt := range []struct {
+		rating Rating
+		age    int
+		want   bool
+	}{
+		{RatingGreen, 3, true},
+		{RatingTurquoise, 6, false},
+		{RatingTurquoise, 7, true},
+		{RatingBlue, 11, false},
+		{RatingBlue, 12, true},
+		{RatingOrange, 15, false},
+		{RatingOrange, 16, true},
+		{RatingUnrated, 40, false},
+		{Rating("RED"), 40, false},
+		{Rating("PURPLE"), 40, false},
+	} {
+		if got := tt.rating.AllowedFor(tt.age); got != tt.want {
+			t.Fatalf("%q.AllowedFor(%d) = %v, want %v", tt.rating, tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestRatingAllowedIn(t *testing.T) {
+	for _, tt := range []struct {
+		rating  Rating
+		country Country
+		age     int
+		want    bool
+	}{
+		{RatingTurquoise, Norway, 6, true},
+		{RatingTurquoise, Sweden, 6, false},
+		{RatingBlue, Sweden, 11, true},
+		{RatingBlue, Finland, 11, false},
+		{RatingUnrated, Finland, 40, false},
+		{Rating("PURPLE"), Sweden, 40, false},
+	} {
+		if got := tt.rating.AllowedIn(tt.country, tt.age); got != tt.want {
+			t.Fatalf("%q.AllowedIn(%q, %d) = %v, want %v", tt.rating, tt.country, tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestResponseFilterRating(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(swedishFullDayEPGResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count := func(r *Response) (n int) {
+		for _, d := range r.Days {
+			for _, c := range d.Channels {
+				n += len(c.Schedules)
+			}
+		}
+
+		return n
+	}
+
+	before := count(&r)
+
+	filtered := r.FilterRating(Sweden, 7, false)
+
+	for _, d := range filtered.Days {
+		for _, c := range d.Channels {
+			for _, s := range c.Schedules {
+				if !s.Program.Rating.AllowedIn(Sweden, 7) {
+					t.Fatalf("schedule %s with rating %q was not removed", s.ID, s.Program.Rating)
+				}
+			}
+		}
+	}
+
+	if got := count(filtered); got == 0 || got >= before {
+		t.Fatalf("count(filtered) = %d, want between 0 and %d", got, before)
+	}
+
+	if got := count(&r); got != before {
+		t.Fatalf("the original response was modified, count = %d, want %d", got, before)
+	}
+
+	titles := func(r *Response) map[string]bool {
+		m := map[string]bool{}
+
+		for _, d := range r.Days {
+			for _, c := range d.Channels {
+				for _, s := range c.Schedules {
+					m[s.Program.Title] = true
+				}
+			}
+		}
+
+		return m
+	}
+
+	unrated := []string{"Enemy at the gates", "Starsky & Hutch", "Reservation Road", "Kvinnofängelset"}
+
+	for _, title := range unrated {
+		if !titles(&r)[title] {
+			t.Fatalf("the response does not contain %q", title)
+		}
+
+		if titles(filtered)[title] {
+			t.Fatalf("unrated %q was not removed", title)
+		}
+
+		if !titles(r.FilterRating(Sweden, 7, true))[title] {
+			t.Fatalf("unrated %q was removed with includeUnrated", title)
+		}
+	}
+}
+
+// TestRatingSchemaDrift fails if the fixtures contain ratings without constants
+func TestRatingSchemaDrift(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"finnish channel 12":    finnishChannel12ResponseXML,
+		"danish two days drama": danishTwoDaysDramaEPGResponseXML,
+		"swedish live sports":   swedishLiveSportsEPGResponseXML,
+		"swedish full day":      swedishFullDayEPGResponseXML,
+	} {
+		var r Response
+
+		if err := xml.Unmarshal(data, &r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, d := range r.Days {
+			for _, c := range d.Channels {
+				for _, s := range c.Schedules {
+					if !s.Program.Rating.Known() {
+						t.Fatalf("%s: unknown rating %q in schedule %s", name, s.Program.Rating, s.ID)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package epg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ChannelInfo is the metadata of a channel, see ChannelsFor and ChannelRegistry.
+// HDID and SDID are the IDs of the HD and SD variants of the channel, if any
+type ChannelInfo struct {
+	ID          string    `json:"channel_id"`
+	Name        string    `json:"name"`
+	Title       string    `json:"title"`
+	LogoID      string    `json:"logo_id,omitempty"`
+	LogoDarkID  string    `json:"logo_dark_id,omitempty"`
+	LogoLightID string    `json:"logo_light_id,omitempty"`
+	IsHD        bool      `json:"hd"`
+	HDID        string    `json:"hd_channel_id,omitempty"`
+	SDID        string    `json:"sd_channel_id,omitempty"`
+	Countries   []Country `json:"countries,omitempty"`
+}
+
+// BroadcastIn reports whether the channel is broadcast in the country
+func (ci ChannelInfo) BroadcastIn(country Country) bool {
+	return hasCountry(ci.Countries, country)
+}
+
+// pairVariants sets the HD and SD variant IDs of the channels. Channels are paired
+// by name, like CMoreStars and CMoreStarsHD, and then by title, like C More First
+// and C More First HD. IsHD is left as decoded, the API does not always mark the
+// HD variant of a pair as HD
+func pairVariants(channels []ChannelInfo) {
+	var (
+		byName  = map[string]int{}
+		byTitle = map[string][]int{}
+	)
+
+	for i := range channels {
+		channels[i].HDID, channels[i].SDID = "", ""
+
+		byName[channels[i].Name] = i
+		byTitle[channels[i].Title] = append(byTitle[channels[i].Title], i)
+	}
+
+	pair := func(sd, hd int) {
+		if sd == hd || channels[sd].HDID != "" || channels[sd].SDID != "" || channels[hd].HDID != "" || channels[hd].SDID != "" {
+			return
+		}
+
+		channels[sd].HDID = channels[hd].ID
+		channels[hd].SDID = channels[sd].ID
+	}
+
+	for i, c := range channels {
+		if base := strings.TrimSuffix(c.Name, "HD"); base != c.Name && base != "" {
+			if j, ok := byName[base]; ok {
+				pair(j, i)
+			}
+		}
+	}
+
+	for i, c := range channels {
+		if base := strings.TrimSuffix(c.Title, " HD"); base != c.Title && base != "" {
+			if js := byTitle[base]; len(js) == 1 {
+				pair(js[0], i)
+			}
+		}
+	}
+}
+
+// ChannelRegistry is a set of channels built from responses, safe for concurrent use.
+// It is persisted as a JSON array of ChannelInfo.
+type ChannelRegistry struct {
+	mu       sync.RWMutex
+	channels map[string]ChannelInfo
+}
+
+// NewChannelRegistry creates an empty ChannelRegistry
+func NewChannelRegistry() *ChannelRegistry {
+	return &ChannelRegistry{channels: map[string]ChannelInfo{}}
+}
+
+// Add adds the channels of the response, as seen in the country, to the registry.
+// Channels already in the registry are updated with the latest metadata, and the
+// HD and SD variants of all channels are paired again
+func (cr *ChannelRegistry) Add(country Country, r *Response) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if cr.channels == nil {
+		cr.channels = map[string]ChannelInfo{}
+	}
+
+	for _, d := range r.Days {
+		for _, c := range d.Channels {
+			if c.ID == "" {
+				continue
+			}
+
+			ci := cr.channels[c.ID]
+
+			ci.ID, ci.Name, ci.Title, ci.IsHD = c.ID, c.Name, c.Title, c.IsHD
+			ci.LogoID, ci.LogoDarkID, ci.LogoLightID = c.LogoID, c.LogoDarkID, c.LogoLightID
+
+			if country != "" && !hasCountry(ci.Countries, country) {
+				ci.Countries = append(append([]Country(nil), ci.Countries...), country)
+
+				sort.Slice(ci.Countries, func(i, j int) bool { return ci.Countries[i] < ci.Countries[j] })
+			}
+
+			cr.channels[c.ID] = ci
+		}
+	}
+
+	channels := make([]ChannelInfo, 0, len(cr.channels))
+
+	for _, ci := range cr.channels {
+		channels = append(channels, ci)
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return lessChannelID(channels[i].ID, channels[j].ID)
+	})
+
+	pairVariants(channels)
+
+	for _, ci := range channels {
+		cr.channels[ci.ID] = ci
+	}
+}
+
+func hasCountry(countries []Country, country Country) bool {
+	for _, c := range countries {
+		if c == country {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of channels in the registry
+func (cr *ChannelRegistry) Len() int {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return len(cr.channels)
+}
+
+// Channels returns all channels in the registry, ordered by ID
+func (cr *ChannelRegistry) Channels() []ChannelInfo {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	channels := make([]ChannelInfo, 0, len(cr.channels))
+
+	for _, ci := range cr.channels {
+		channels = append(channels, ci)
+	}
+
+	sort.Slice(channels, func(i, j int) bool {
+		return lessChannelID(channels[i].ID, channels[j].ID)
+	})
+
+	return channels
+}
+
+// lessChannelID orders numeric channel IDs numerically, and other IDs after them
+func lessChannelID(a, b string) bool {
+	na, erra := strconv.Atoi(a)
+	nb, errb := strconv.Atoi(b)
+
+	switch {
+	case erra == nil && errb == nil:
+		return na < nb
+	case erra == nil:
+		return true
+	case errb == nil:
+		return false
+	default:
+		return a < b
+	}
+}
+
+// Channel returns the channel with the given id.
+// Returns empty ChannelInfo if not found
+func (cr *ChannelRegistry) Channel(id string) ChannelInfo {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	return cr.channels[id]
+}
+
+// ChannelByName returns the channel with the given name, e.g. "CMoreStarsHD".
+// Returns empty ChannelInfo if not found
+func (cr *ChannelRegistry) ChannelByName(name string) ChannelInfo {
+	for _, ci := range cr.Channels() {
+		if ci.Name == name {
+			return ci
+		}
+	}
+
+	return ChannelInfo{}
+}
+
+// ChannelByTitle returns the channel with the given title, e.g. "C More Stars HD".
+// Returns empty ChannelInfo if not found
+func (cr *ChannelRegistry) ChannelByTitle(title string) ChannelInfo {
+	for _, ci := range cr.Channels() {
+		if ci.Title == title {
+			return ci
+		}
+	}
+
+	return ChannelInfo{}
+}
+
+// Search returns the channels with an ID, name or title matching the query,
+// ignoring case, spaces and punctuation. Exact matches come first, followed by
+// prefix, substring and fuzzy matches where the query letters appear in order
+func (cr *ChannelRegistry) Search(query string) []ChannelInfo {
+	q := normalizeChannelName(query)
+
+	if q == "" {
+		return nil
+	}
+
+	type match struct {
+		ci    ChannelInfo
+		score int
+	}
+
+	var matches []match
+
+	for _, ci := range cr.Channels() {
+		best := -1
+
+		for _, s := range []string{ci.ID, ci.Name, ci.Title} {
+			if score := matchScore(normalizeChannelName(s), q); score >= 0 && (best < 0 || score < best) {
+				best = score
+			}
+		}
+
+		if best >= 0 {
+			matches = append(matches, match{ci, best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+
+	channels := make([]ChannelInfo, len(matches))
+
+	for i, m := range matches {
+		channels[i] = m.ci
+	}
+
+	return channels
+}
+
+// matchScore returns how well s matches q, lower is better, or -1 if it does not match
+func matchScore(s, q string) int {
+	switch {
+	case s == "":
+		return -1
+	case s == q:
+		return 0
+	case strings.HasPrefix(s, q):
+		return 1
+	case strings.Contains(s, q):
+		return 2
+	}
+
+	rs := []rune(s)
+
+	i := 0
+
+	for _, r := range q {
+		for i < len(rs) && rs[i] != r {
+			i++
+		}
+
+		if i == len(rs) {
+			return -1
+		}
+
+		i++
+	}
+
+	return 3
+}
+
+// normalizeChannelName lowercases s and removes everything but letters and digits
+func normalizeChannelName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, s)
+}
+
+// MarshalJSON marshals the registry as an array of channels ordered by ID
+func (cr *ChannelRegistry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cr.Channels())
+}
+
+// UnmarshalJSON replaces the channels of the registry with an array of channels
+func (cr *ChannelRegistry) UnmarshalJSON(b []byte) error {
+	var channels []ChannelInfo
+
+	if err := json.Unmarshal(b, &channels); err != nil {
+		return err
+	}
+
+	m := make(map[string]ChannelInfo, len(channels))
+
+	for _, ci := range channels {
+		m[ci.ID] = ci
+	}
+
+	cr.mu.Lock()
+	cr.channels = m
+	cr.mu.Unlock()
+
+	return nil
+}
+
+// UpdateChannels adds the channels of the date in each of the countries to the registry
+func (c *Client) UpdateChannels(ctx context.Context, cr *ChannelRegistry, date string, countries ...Country) error {
+	for _, country := range countries {
+		r, err := c.Get(ctx, country, country.Language(), date)
+		if err != nil {
+			return err
+		}
+
+		cr.Add(country, r)
+	}
+
+	return nil
+}
+
+// RefreshChannels updates the registry with the channels of the current date in
+// each of the countries, and then again every interval until ctx is done, when
+// it returns ctx.Err(). Only an error from the first update is returned, the
+// registry keeps its channels when later updates fail. Returns an error without
+// updating if the interval is not positive
+func (c *Client) RefreshChannels(ctx context.Context, cr *ChannelRegistry, interval time.Duration, countries ...Country) error {
+	if interval <= 0 {
+		return fmt.Errorf("non-positive refresh interval %v", interval)
+	}
+
+	if err := c.UpdateChannels(ctx, cr, DateAtTime(time.Now().In(Stockholm)), countries...); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_ = c.UpdateChannels(ctx, cr, DateAtTime(time.Now().In(Stockholm)), countries...)
+		}
+	}
+}
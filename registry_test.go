@@ -0,0 +1,259 @@
+package epg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testChannelRegistry(t *testing.T) *ChannelRegistry {
+	t.Helper()
+
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	cr := NewChannelRegistry()
+
+	ctx := context.Background()
+
+	if err := c.UpdateChannels(ctx, cr, "2017-01-25", Sweden); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r, err := c.GetChannel(ctx, Finland, Finnish, "2017-01-27", "2017-01-27", CanalHD)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cr.Add(Finland, r)
+
+	return cr
+}
+
+func TestChannelRegistry(t *testing.T) {
+	cr := testChannelRegistry(t)
+
+	if got := cr.Len(); got == 0 {
+		t.Fatalf("cr.Len() = 0, want channels")
+	}
+
+	tv4 := cr.Channel(TV4)
+
+	if got, want := tv4.Name, "TV4"; got != want {
+		t.Fatalf("tv4.Name = %q, want %q", got, want)
+	}
+
+	if got, want := len(tv4.Countries), 1; got != want || tv4.Countries[0] != Sweden {
+		t.Fatalf("tv4.Countries = %v, want [se]", tv4.Countries)
+	}
+
+	canal := cr.Channel(CanalHD)
+
+	if got, want := canal.Countries, []Country{Finland, Sweden}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("canal.Countries = %v, want %v", got, want)
+	}
+
+	if got, want := cr.ChannelByName("CMoreStarsHD").Title, "C More Stars HD"; got != want {
+		t.Fatalf("ChannelByName(CMoreStarsHD).Title = %q, want %q", got, want)
+	}
+
+	if got, want := cr.ChannelByTitle("C More Stars HD").Name, "CMoreStarsHD"; got != want {
+		t.Fatalf("ChannelByTitle(C More Stars HD).Name = %q, want %q", got, want)
+	}
+
+	if stars := cr.Channel(CMoreStars); stars.HDID != CMoreStarsHD || cr.Channel(CMoreStarsHD).SDID != CMoreStars {
+		t.Fatalf("CMoreStars and CMoreStarsHD are not paired: %+v", stars)
+	}
+
+	if got := cr.Channel(CMoreStarsHD); got.IsHD {
+		t.Fatalf("cr.Channel(CMoreStarsHD).IsHD = true, want false as in the response")
+	}
+
+	if got := cr.Channel("unknown"); got.ID != "" {
+		t.Fatalf("cr.Channel(unknown) = %+v, want empty ChannelInfo", got)
+	}
+
+	channels := cr.Channels()
+
+	for i := 1; i < len(channels); i++ {
+		if !lessChannelID(channels[i-1].ID, channels[i].ID) {
+			t.Fatalf("channels are not ordered by ID: %q before %q", channels[i-1].ID, channels[i].ID)
+		}
+	}
+}
+
+func TestPairVariants(t *testing.T) {
+	channels := []ChannelInfo{
+		{ID: "1", Name: "CMoreGolf"},
+		{ID: "2", Name: "CMoreGolfHD", Title: "C More Golf HD"},
+		{ID: "3", Name: "CMoreGolfDenmarkHD", Title: "C More Golf HD"},
+		{ID: "4", Name: "CanalFilm1", Title: "C More First"},
+		{ID: "5", Name: "CanalHD", Title: "C More First HD", IsHD: true},
+		{ID: "6", Name: "HD", Title: "HD"},
+		{ID: "7", Name: "TV4", Title: "TV4", HDID: "stale"},
+	}
+
+	pairVariants(channels)
+
+	for _, tt := range []struct {
+		i    int
+		hdID string
+		sdID string
+		isHD bool
+	}{
+		{0, "2", "", false},
+		{1, "", "1", false},
+		{2, "", "", false},
+		{3, "5", "", false},
+		{4, "", "4", true},
+		{5, "", "", false},
+		{6, "", "", false},
+	} {
+		c := channels[tt.i]
+
+		if c.HDID != tt.hdID || c.SDID != tt.sdID || c.IsHD != tt.isHD {
+			t.Fatalf("channels[%d] = %+v, want HDID %q, SDID %q, IsHD %v", tt.i, c, tt.hdID, tt.sdID, tt.isHD)
+		}
+	}
+}
+
+func TestChannelRegistrySearch(t *testing.T) {
+	cr := testChannelRegistry(t)
+
+	for _, tt := range []struct {
+		query string
+		first string
+	}{
+		{"c more stars hd", "CMoreStarsHD"},
+		{"CMORESTARS", "CMoreStars"},
+		{"tv4", "TV4"},
+		{"76", "TV4"},
+		{"svt kunskap", "SVTKunskapskanalen"},
+		{"kunskapskan", "SVTKunskapskanalen"},
+		{"cmrgolf", "CMoreGolfHD"},
+	} {
+		channels := cr.Search(tt.query)
+
+		if len(channels) == 0 {
+			t.Fatalf("cr.Search(%q) returned no channels", tt.query)
+		}
+
+		if got := channels[0].Name; got != tt.first {
+			t.Fatalf("cr.Search(%q)[0].Name = %q, want %q", tt.query, got, tt.first)
+		}
+	}
+
+	if got := cr.Search("no such channel"); len(got) != 0 {
+		t.Fatalf("cr.Search(no such channel) = %v, want none", got)
+	}
+
+	if got := cr.Search(" - "); got != nil {
+		t.Fatalf("cr.Search( - ) = %v, want nil", got)
+	}
+}
+
+func TestChannelRegistryJSON(t *testing.T) {
+	cr := testChannelRegistry(t)
+
+	b, err := json.Marshal(cr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded := NewChannelRegistry()
+
+	if err := json.Unmarshal(b, loaded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := loaded.Len(), cr.Len(); got != want {
+		t.Fatalf("loaded.Len() = %d, want %d", got, want)
+	}
+
+	b2, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.Equal(b, b2) {
+		t.Fatalf("loaded registry marshals differently")
+	}
+
+	if err := json.Unmarshal([]byte(`{}`), loaded); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestRefreshChannels(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write(finnishChannel12ResponseXML)
+	}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL))
+	cr := NewChannelRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() { done <- c.RefreshChannels(ctx, cr, time.Millisecond, Finland) }()
+
+	for atomic.LoadInt32(&requests) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	if got := cr.Channel(CanalHD).Countries; len(got) != 1 || got[0] != Finland {
+		t.Fatalf("Countries = %v, want [fi]", got)
+	}
+}
+
+func TestRefreshChannelsError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL))
+
+	err := c.RefreshChannels(context.Background(), NewChannelRegistry(), time.Hour, Sweden)
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("err = %v, want ErrServerError", err)
+	}
+}
+
+func TestRefreshChannelsInterval(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer ts.Close()
+
+	c := NewClient(BaseURL(ts.URL))
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := c.RefreshChannels(context.Background(), NewChannelRegistry(), interval, Sweden); err == nil {
+			t.Fatalf("RefreshChannels with interval %v returned no error", interval)
+		}
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Fatalf("requests = %d, want 0", got)
+	}
+}
@@ -0,0 +1,135 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy describes how the Client retries requests that failed
+// because of transient network errors, rate limiting (429) or server errors (5xx)
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+
+	// MinBackoff is the backoff before the first retry
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the exponential backoff between attempts. Requests are
+	// not retried when a Retry-After header asks for a longer wait
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a sensible RetryPolicy for batch jobs
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  250 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// Retry changes the *client retry policy to the provided RetryPolicy
+func Retry(policy RetryPolicy) func(*Client) {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// attempts returns the maximum number of attempts, never less than one
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return transient(err)
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// transient reports whether err is a network error that may not happen again,
+// like a timeout or a connection reset, as opposed to e.g. an invalid URL or
+// certificate, or a canceled context
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var ne net.Error
+
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+
+	for _, target := range []error{io.EOF, io.ErrUnexpectedEOF, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the time to wait after the given (1-based) attempt,
+// preferring the Retry-After header of the response if there is one.
+// Reports false if the Retry-After header asks for more than MaxBackoff
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return d, p.MaxBackoff <= 0 || d <= p.MaxBackoff
+		}
+	}
+
+	d := p.MinBackoff
+
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if d <= 0 {
+		return 0, true
+	}
+
+	// Equal jitter, half of the backoff is fixed and the other half random
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1)), true
+}
+
+// retryAfter parses a Retry-After header value in either delay-seconds or HTTP-date format
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+
+	if s, err := strconv.Atoi(v); err == nil {
+		if s < 0 {
+			return 0, false
+		}
+
+		return time.Duration(s) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,203 @@
+package epg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	t.Run("recovers", func(t *testing.T) {
+		ts, calls := testFailingServer(2, http.StatusBadGateway, "")
+		defer ts.Close()
+
+		r, err := NewClient(BaseURL(ts.URL), Retry(policy)).Get(context.Background(), Sweden, Swedish, "2017-01-25")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got, want := atomic.LoadInt32(calls), int32(3); got != want {
+			t.Fatalf("calls = %d, want %d", got, want)
+		}
+
+		if got, want := (*r.Meta)["attempts"], 3; got != want {
+			t.Fatalf(`(*r.Meta)["attempts"] = %v, want %v`, got, want)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		ts, calls := testFailingServer(5, http.StatusTooManyRequests, "0")
+		defer ts.Close()
+
+		_, err := NewClient(BaseURL(ts.URL), Retry(policy)).Get(context.Background(), Sweden, Swedish, "2017-01-25")
+		if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("err = %v, want ErrRateLimited", err)
+		}
+
+		if got, want := atomic.LoadInt32(calls), int32(3); got != want {
+			t.Fatalf("calls = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		ts, calls := testFailingServer(5, http.StatusNotFound, "")
+		defer ts.Close()
+
+		_, err := NewClient(BaseURL(ts.URL), Retry(policy)).Get(context.Background(), Sweden, Swedish, "2017-01-25")
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("err = %v, want ErrNotFound", err)
+		}
+
+		if got, want := atomic.LoadInt32(calls), int32(1); got != want {
+			t.Fatalf("calls = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("long Retry-After", func(t *testing.T) {
+		ts, calls := testFailingServer(5, http.StatusServiceUnavailable, "3600")
+		defer ts.Close()
+
+		_, err := NewClient(BaseURL(ts.URL), Retry(policy)).Get(context.Background(), Sweden, Swedish, "2017-01-25")
+		if !errors.Is(err, ErrServerError) {
+			t.Fatalf("err = %v, want ErrServerError", err)
+		}
+
+		if got, want := atomic.LoadInt32(calls), int32(1); got != want {
+			t.Fatalf("calls = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ts, calls := testFailingServer(5, http.StatusServiceUnavailable, "60")
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		// Retry-After is within MaxBackoff, but beyond the deadline
+		policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Hour}
+
+		_, err := NewClient(BaseURL(ts.URL), Retry(policy)).Get(ctx, Sweden, Swedish, "2017-01-25")
+		if !errors.Is(err, ErrServerError) {
+			t.Fatalf("err = %v, want ErrServerError", err)
+		}
+
+		if got, want := atomic.LoadInt32(calls), int32(1); got != want {
+			t.Fatalf("calls = %d, want %d", got, want)
+		}
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	for _, tt := range []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{1, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 200 * time.Millisecond, 400 * time.Millisecond},
+		{10, 500 * time.Millisecond, time.Second},
+	} {
+		if d, ok := p.backoff(tt.attempt, nil); !ok || d < tt.min || d > tt.max {
+			t.Fatalf("p.backoff(%d, nil) = %s, %v, want between %s and %s", tt.attempt, d, ok, tt.min, tt.max)
+		}
+	}
+
+	for _, tt := range []struct {
+		retryAfter string
+		want       time.Duration
+		ok         bool
+	}{
+		{"1", time.Second, true},
+		{"3600", time.Hour, false},
+	} {
+		resp := &http.Response{Header: http.Header{"Retry-After": {tt.retryAfter}}}
+
+		if d, ok := p.backoff(1, resp); d != tt.want || ok != tt.ok {
+			t.Fatalf("p.backoff with Retry-After %s = %s, %v, want %s, %v", tt.retryAfter, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	var p RetryPolicy
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", &net.OpError{Op: "read", Err: timeoutError{}}, true},
+		{"reset", &url.Error{Op: "Get", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, true},
+		{"refused", &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, true},
+		{"eof", &url.Error{Op: "Get", Err: io.EOF}, true},
+		{"canceled", &url.Error{Op: "Get", Err: context.Canceled}, false},
+		{"deadline", &url.Error{Op: "Get", Err: context.DeadlineExceeded}, false},
+		{"permanent", &url.Error{Op: "Get", Err: errors.New("unsupported protocol scheme")}, false},
+	} {
+		if got := p.retryable(nil, tt.err); got != tt.want {
+			t.Fatalf("p.retryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	} {
+		d, ok := retryAfter(tt.value)
+
+		if d != tt.want || ok != tt.ok {
+			t.Fatalf("retryAfter(%q) = %s, %v, want %s, %v", tt.value, d, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// testFailingServer responds with status the first failures requests, then with an empty EPG
+func testFailingServer(failures int32, status int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= failures {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+
+				w.WriteHeader(status)
+				return
+			}
+
+			w.Write(emptyEPGResponseXML)
+		}))
+
+	return ts, &calls
+}
@@ -0,0 +1,63 @@
+package epg
+
+import "time"
+
+// Start returns the start time of the schedule, or the zero time if unknown
+func (s Schedule) Start() time.Time {
+	if !s.CalendarDate.Valid() {
+		return time.Time{}
+	}
+
+	return s.CalendarDate.Time
+}
+
+// End returns the end time of the schedule, based on the duration of the
+// program if known, or NextStart otherwise. Returns the zero time if unknown
+func (s Schedule) End() time.Time {
+	start := s.Start()
+
+	if start.IsZero() {
+		return time.Time{}
+	}
+
+	if d := s.Program.DurationTime(); d > 0 {
+		return start.Add(d)
+	}
+
+	if s.NextStart.Valid() && s.NextStart.After(start) {
+		return s.NextStart.Time
+	}
+
+	return time.Time{}
+}
+
+// Slot returns the duration of the time slot of the schedule, from its start
+// until NextStart. Returns 0 if either of them is unknown
+func (s Schedule) Slot() time.Duration {
+	start := s.Start()
+
+	if start.IsZero() || !s.NextStart.Valid() || !s.NextStart.After(start) {
+		return 0
+	}
+
+	return s.NextStart.Sub(start)
+}
+
+// stop returns NextStart if known, or the end of the schedule otherwise.
+// Returns the zero time if unknown
+func (s Schedule) stop() time.Time {
+	if start := s.Start(); !start.IsZero() && s.NextStart.Valid() && s.NextStart.After(start) {
+		return s.NextStart.Time
+	}
+
+	return s.End()
+}
+
+// DurationTime returns the duration of the program as a time.Duration
+func (p Program) DurationTime() time.Duration {
+	if p.Duration < 0 {
+		return 0
+	}
+
+	return time.Duration(p.Duration) * time.Minute
+}
@@ -0,0 +1,77 @@
+package epg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	var (
+		start     = time.Date(2017, 1, 25, 19, 0, 0, 0, Stockholm)
+		nextStart = time.Date(2017, 1, 25, 19, 30, 0, 0, Stockholm)
+	)
+
+	for _, tt := range []struct {
+		name     string
+		schedule Schedule
+		start    time.Time
+		end      time.Time
+		slot     time.Duration
+	}{
+		{"empty", Schedule{}, time.Time{}, time.Time{}, 0},
+		{
+			"duration",
+			Schedule{CalendarDate: Time{start}, NextStart: Time{nextStart}, Program: Program{Duration: 25}},
+			start, start.Add(25 * time.Minute), 30 * time.Minute,
+		},
+		{
+			"next start",
+			Schedule{CalendarDate: Time{start}, NextStart: Time{nextStart}},
+			start, nextStart, 30 * time.Minute,
+		},
+		{
+			"sentinel next start",
+			Schedule{CalendarDate: Time{start}, NextStart: Time{maxTime}},
+			start, time.Time{}, 0,
+		},
+		{
+			"next start before start",
+			Schedule{CalendarDate: Time{nextStart}, NextStart: Time{start}},
+			nextStart, time.Time{}, 0,
+		},
+		{
+			"sentinel start",
+			Schedule{NextStart: Time{nextStart}, Program: Program{Duration: 25}},
+			time.Time{}, time.Time{}, 0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Start(); !got.Equal(tt.start) {
+				t.Fatalf("Start() = %v, want %v", got, tt.start)
+			}
+
+			if got := tt.schedule.End(); !got.Equal(tt.end) {
+				t.Fatalf("End() = %v, want %v", got, tt.end)
+			}
+
+			if got := tt.schedule.Slot(); got != tt.slot {
+				t.Fatalf("Slot() = %v, want %v", got, tt.slot)
+			}
+		})
+	}
+}
+
+func TestProgramDurationTime(t *testing.T) {
+	for _, tt := range []struct {
+		duration int
+		want     time.Duration
+	}{
+		{0, 0},
+		{-1, 0},
+		{89, 89 * time.Minute},
+	} {
+		if got := (Program{Duration: tt.duration}).DurationTime(); got != tt.want {
+			t.Fatalf("Program{Duration: %d}.DurationTime() = %v, want %v", tt.duration, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package epg
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ScheduleFunc is called for each schedule when streaming a response.
+//
+// The Day and Channel only contain the attributes of the enclosing elements,
+// their Channels and Schedules are never populated. Returning an error stops
+// the stream, and the error is returned to the caller.
+type ScheduleFunc func(Day, Channel, Schedule) error
+
+// StreamPeriod streams the schedules for the period fromDate until toDate,
+// calling fn for each schedule as it is decoded. Unlike GetPeriod it does not
+// buffer the response, so it can process arbitrarily long periods in constant memory.
+func (c *Client) StreamPeriod(ctx context.Context, country Country, language Language, fromDate, toDate string, fn ScheduleFunc, attributes ...url.Values) error {
+	ex := &Exchange{
+		Path:  c.getPeriodPath(country, language, fromDate, toDate),
+		Query: c.query(attributes),
+	}
+
+	resp, err := c.do(ctx, ex, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_, _ = io.CopyN(ioutil.Discard, resp.Body, 64)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return stream(resp.Body, c.location(country), fn)
+}
+
+// stream decodes an Epg document token by token, with the naive local
+// timestamps in loc, calling fn for each schedule
+func stream(r io.Reader, loc *time.Location, fn ScheduleFunc) error {
+	var (
+		dec     = xml.NewTokenDecoder(localTimes{xml.NewDecoder(r), loc})
+		day     Day
+		channel Channel
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch se.Name.Local {
+		case "Day":
+			day = Day{}
+
+			for _, attr := range se.Attr {
+				if attr.Name.Local == "BroadcastDate" {
+					if err := day.BroadcastDate.UnmarshalXMLAttr(attr); err != nil {
+						return err
+					}
+
+					day.BroadcastDate = day.BroadcastDate.in(loc)
+				}
+			}
+		case "Channel":
+			if channel, err = channelAttrs(se.Attr); err != nil {
+				return err
+			}
+		case "Schedule":
+			var s Schedule
+
+			if err := dec.DecodeElement(&s, &se); err != nil {
+				return err
+			}
+
+			if err := fn(day, channel, s.in(loc)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// channelAttrs returns a Channel based on the attributes of a Channel element
+func channelAttrs(attrs []xml.Attr) (Channel, error) {
+	var c Channel
+
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "ChannelId":
+			c.ID = attr.Value
+		case "Name":
+			c.Name = attr.Value
+		case "Title":
+			c.Title = attr.Value
+		case "LogoId":
+			c.LogoID = attr.Value
+		case "LogoDarkId":
+			c.LogoDarkID = attr.Value
+		case "LogoLightId":
+			c.LogoLightID = attr.Value
+		case "IsHd":
+			hd, err := strconv.ParseBool(attr.Value)
+			if err != nil {
+				return Channel{}, err
+			}
+
+			c.IsHD = hd
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,105 @@
+package epg
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestStreamPeriod(t *testing.T) {
+	ts, c := testServerAndClient()
+	defer ts.Close()
+
+	want, err := decode(bytes.NewReader(danishTwoDaysDramaEPGResponseXML), Copenhagen)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type tuple struct {
+		date      Time
+		channelID string
+		schedule  Schedule
+	}
+
+	var expected []tuple
+
+	for _, d := range want.Days {
+		for _, c := range d.Channels {
+			for _, s := range c.Schedules {
+				expected = append(expected, tuple{d.BroadcastDate, c.ID, s})
+			}
+		}
+	}
+
+	var streamed []tuple
+
+	err = c.StreamPeriod(context.Background(), Denmark, Danish, "2017-01-26", "2017-01-27",
+		func(d Day, c Channel, s Schedule) error {
+			if d.Channels != nil || c.Schedules != nil {
+				t.Fatalf("unexpected nested data in streamed Day or Channel")
+			}
+
+			streamed = append(streamed, tuple{d.BroadcastDate, c.ID, s})
+
+			return nil
+		},
+		url.Values{"genre": {"drama"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(streamed), len(expected); got != want {
+		t.Fatalf("len(streamed) = %d, want %d", got, want)
+	}
+
+	if !reflect.DeepEqual(streamed, expected) {
+		t.Fatalf("streamed schedules differ from decoded schedules")
+	}
+}
+
+func TestStreamStop(t *testing.T) {
+	var (
+		errStop = errors.New("stop")
+		count   int
+	)
+
+	err := stream(bytes.NewReader(swedishFullDayEPGResponseXML), Stockholm, func(d Day, c Channel, s Schedule) error {
+		if count++; count == 3 {
+			return errStop
+		}
+
+		return nil
+	})
+
+	if err != errStop {
+		t.Fatalf("err = %v, want %v", err, errStop)
+	}
+
+	if got, want := count, 3; got != want {
+		t.Fatalf("count = %d, want %d", got, want)
+	}
+}
+
+func TestChannelAttrs(t *testing.T) {
+	c, err := channelAttrs([]xml.Attr{
+		{Name: xml.Name{Local: "ChannelId"}, Value: "12"},
+		{Name: xml.Name{Local: "Name"}, Value: "CanalHD"},
+		{Name: xml.Name{Local: "IsHd"}, Value: "true"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := c, (Channel{ID: "12", Name: "CanalHD", IsHD: true}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("c = %#v, want %#v", got, want)
+	}
+
+	if _, err := channelAttrs([]xml.Attr{{Name: xml.Name{Local: "IsHd"}, Value: "maybe"}}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
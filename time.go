@@ -4,14 +4,46 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"time"
+	_ "time/tzdata" // the time zones must be available on systems without tzdata
 )
 
-// Stockholm is the Time Zone in Sweden
-var Stockholm *time.Location
+// The time zones of the countries in the EPG. The time zone database is
+// embedded, so they are never nil
+var (
+	// Stockholm is the Time Zone in Sweden
+	Stockholm = loadLocation("Europe/Stockholm")
 
-func init() {
-	if location, err := time.LoadLocation("Europe/Stockholm"); err == nil {
-		Stockholm = location
+	// Oslo is the Time Zone in Norway
+	Oslo = loadLocation("Europe/Oslo")
+
+	// Copenhagen is the Time Zone in Denmark
+	Copenhagen = loadLocation("Europe/Copenhagen")
+
+	// Helsinki is the Time Zone in Finland
+	Helsinki = loadLocation("Europe/Helsinki")
+)
+
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return location
+}
+
+// Location returns the time zone of the country, used for the naive local
+// timestamps in responses. Returns Stockholm for unknown countries
+func (c Country) Location() *time.Location {
+	switch c {
+	case Norway:
+		return Oslo
+	case Denmark:
+		return Copenhagen
+	case Finland:
+		return Helsinki
+	default:
+		return Stockholm
 	}
 }
 
@@ -20,14 +52,31 @@ type Time struct {
 	time.Time
 }
 
+// xmlTimeFormat is the format of the naive local timestamps used by the API
+const xmlTimeFormat = "2006-01-02T15:04:05"
+
+// maxTime is the 9999-12-31T23:59:59 sentinel value used by the API for "no end"
+var maxTime = time.Date(9999, time.December, 31, 23, 59, 59, 0, time.UTC)
+
+// Valid reports whether the time is set, meaning that it is neither zero,
+// nor one of the 0001-01-01 or 9999-12-31 sentinel values used by the API
+func (t Time) Valid() bool {
+	return !t.IsZero() && t.Year() > 1 && t.Year() < 9999
+}
+
 // UnmarshalXMLAttr handles special cases like 0001-01-01T00:00:00+01:00
-// and 9999-12-31T23:59:59+01:00
+// and 9999-12-31T23:59:59+01:00, which are decoded as the zero time and 9999-12-31T23:59:59Z.
+// Naive local timestamps are parsed in Stockholm, the Client decodes them in the time zone of the country
 func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
 	switch attr.Value {
-	case "0001-01-01T00:00:00+01:00":
-		attr.Value = "0001-01-01T00:00:00+00:00"
-	case "9999-12-31T23:59:59+01:00":
-		attr.Value = "9999-12-31T23:59:59+00:00"
+	case "0001-01-01T00:00:00+01:00", "0001-01-01T00:00:00":
+		*t = Time{}
+
+		return nil
+	case "9999-12-31T23:59:59+01:00", "9999-12-31T23:59:59":
+		*t = Time{maxTime}
+
+		return nil
 	}
 
 	var format string
@@ -43,7 +92,7 @@ func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
 
 		return nil
 	case 19:
-		format = "2006-01-02T15:04:05"
+		format = xmlTimeFormat
 	case 10:
 		format = "2006-01-02"
 	case 0:
@@ -60,11 +109,109 @@ func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
 	return nil
 }
 
-// MarshalJSON marshals the Time
-func (t *Time) MarshalJSON() ([]byte, error) {
+// in returns the time in loc, leaving the zero time and sentinel values as is
+func (t Time) in(loc *time.Location) Time {
+	if !t.Valid() {
+		return t
+	}
+
+	return Time{t.In(loc)}
+}
+
+// in converts all times of the response to loc
+func (r *Response) in(loc *time.Location) {
+	r.FromDate, r.UntilDate = r.FromDate.in(loc), r.UntilDate.in(loc)
+
+	for i := range r.Days {
+		d := &r.Days[i]
+
+		d.BroadcastDate = d.BroadcastDate.in(loc)
+
+		for j := range d.Channels {
+			for k, s := range d.Channels[j].Schedules {
+				d.Channels[j].Schedules[k] = s.in(loc)
+			}
+		}
+	}
+}
+
+// in returns the schedule with all times converted to loc
+func (s Schedule) in(loc *time.Location) Schedule {
+	s.NextStart, s.CalendarDate = s.NextStart.in(loc), s.CalendarDate.in(loc)
+
+	p := &s.Program
+
+	p.FirstCalendarDate, p.LastCalendarDate = p.FirstCalendarDate.in(loc), p.LastCalendarDate.in(loc)
+	p.VodStart, p.VodEnd = p.VodStart.in(loc), p.VodEnd.in(loc)
+
+	return s
+}
+
+// localTimes is an xml.TokenReader that adds the offset in loc to naive local timestamps in attributes
+type localTimes struct {
+	r   xml.TokenReader
+	loc *time.Location
+}
+
+func (lt localTimes) Token() (xml.Token, error) {
+	tok, err := lt.r.Token()
+
+	if se, ok := tok.(xml.StartElement); ok {
+		for i, attr := range se.Attr {
+			if len(attr.Value) != len(xmlTimeFormat) {
+				continue
+			}
+
+			t, perr := time.ParseInLocation(xmlTimeFormat, attr.Value, lt.loc)
+
+			if perr == nil && (Time{t}).Valid() {
+				se.Attr[i].Value = t.Format(time.RFC3339)
+			}
+		}
+
+		tok = se
+	}
+
+	return tok, err
+}
+
+// MarshalXMLAttr marshals the Time as a naive local timestamp like the API does,
+// with the zero time as 0001-01-01T00:00:00 and 9999-12-31T23:59:59Z as 9999-12-31T23:59:59
+func (t Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	switch {
+	case t.IsZero():
+		return xml.Attr{Name: name, Value: "0001-01-01T00:00:00"}, nil
+	case t.Equal(maxTime):
+		return xml.Attr{Name: name, Value: "9999-12-31T23:59:59"}, nil
+	}
+
+	return xml.Attr{Name: name, Value: t.Format(xmlTimeFormat)}, nil
+}
+
+// MarshalJSON marshals the Time, with the zero time as null
+func (t Time) MarshalJSON() ([]byte, error) {
 	if t.IsZero() {
 		return []byte("null"), nil
 	}
 
 	return json.Marshal(t.Time)
 }
+
+// UnmarshalJSON unmarshals the Time, with null as the zero time
+func (t *Time) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*t = Time{}
+
+		return nil
+	}
+
+	var pt time.Time
+
+	if err := json.Unmarshal(b, &pt); err != nil {
+		return err
+	}
+
+	*t = Time{pt}
+
+	return nil
+}
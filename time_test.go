@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +23,10 @@ func TestUnmarshalXMLAttr(t *testing.T) {
 		{xml.Attr{Value: "2017-01-02T14:28:56+02:00"}, time.Date(2017, 1, 2, 13, 28, 56, 0, Stockholm), nil},
 		{xml.Attr{Value: "0001-01-01T00:00:00Z"}, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), nil},
 		{xml.Attr{Value: "9999-12-31T23:59:59Z"}, time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), nil},
+		{xml.Attr{Value: "0001-01-01T00:00:00"}, time.Time{}, nil},
+		{xml.Attr{Value: "0001-01-01T00:00:00+01:00"}, time.Time{}, nil},
+		{xml.Attr{Value: "9999-12-31T23:59:59"}, time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), nil},
+		{xml.Attr{Value: "9999-12-31T23:59:59+01:00"}, time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), nil},
 		{xml.Attr{Value: "not-a-date"}, time.Time{}, errors.New(
 			`parsing time "not-a-date" as "2006-01-02": cannot parse "not-a-date" as "2006"`,
 		)},
@@ -45,6 +50,22 @@ func TestUnmarshalXMLAttr(t *testing.T) {
 	}
 }
 
+func TestTimeValid(t *testing.T) {
+	for _, tt := range []struct {
+		time Time
+		want bool
+	}{
+		{Time{}, false},
+		{Time{time.Date(1, 1, 1, 0, 0, 0, 0, Stockholm)}, false},
+		{Time{maxTime}, false},
+		{Time{time.Date(2017, 1, 25, 19, 0, 0, 0, Stockholm)}, true},
+	} {
+		if got := tt.time.Valid(); got != tt.want {
+			t.Fatalf("%v.Valid() = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}
+
 func TestMarshalJSON(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		var b bytes.Buffer
@@ -130,3 +151,147 @@ func TestMarshalJSON(t *testing.T) {
 		}
 	})
 }
+
+func TestMarshalJSONValue(t *testing.T) {
+	v := struct {
+		Time Time `json:"time"`
+		Zero Time `json:"zero"`
+	}{Time: Time{time.Date(2017, time.January, 22, 16, 49, 0, 0, Stockholm)}}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := string(b), `{"time":"2017-01-22T16:49:00+01:00","zero":null}`; got != want {
+		t.Fatalf("string(b) = %q, want %q", got, want)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	for _, tt := range []struct {
+		data string
+		want time.Time
+	}{
+		{`null`, time.Time{}},
+		{`"2017-01-22T16:49:00+01:00"`, time.Date(2017, time.January, 22, 16, 49, 0, 0, Stockholm)},
+		{`"9999-12-31T23:59:59Z"`, maxTime},
+	} {
+		tm := Time{time.Now()}
+
+		if err := json.Unmarshal([]byte(tt.data), &tm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !tm.Equal(tt.want) || tm.IsZero() != tt.want.IsZero() {
+			t.Fatalf("json.Unmarshal(%s) = %v, want %v", tt.data, tm, tt.want)
+		}
+	}
+
+	var tm Time
+
+	if err := json.Unmarshal([]byte(`"not a time"`), &tm); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestMarshalXMLAttr(t *testing.T) {
+	for _, tt := range []struct {
+		time Time
+		want string
+	}{
+		{Time{}, "0001-01-01T00:00:00"},
+		{Time{maxTime}, "9999-12-31T23:59:59"},
+		{Time{time.Date(2017, time.January, 22, 16, 49, 0, 0, Stockholm)}, "2017-01-22T16:49:00"},
+		{Time{time.Date(2017, time.May, 22, 16, 49, 0, 0, Stockholm)}, "2017-05-22T16:49:00"},
+	} {
+		attr, err := tt.time.MarshalXMLAttr(xml.Name{Local: "CalendarDate"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if attr.Name.Local != "CalendarDate" || attr.Value != tt.want {
+			t.Fatalf("attr = %+v, want CalendarDate=%q", attr, tt.want)
+		}
+
+		var got Time
+
+		if err := got.UnmarshalXMLAttr(attr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !got.Equal(tt.time.Time) {
+			t.Fatalf("got %v, want %v", got, tt.time)
+		}
+	}
+}
+
+func TestCountryLocation(t *testing.T) {
+	for _, tt := range []struct {
+		country Country
+		want    string
+	}{
+		{Sweden, "Europe/Stockholm"},
+		{Norway, "Europe/Oslo"},
+		{Denmark, "Europe/Copenhagen"},
+		{Finland, "Europe/Helsinki"},
+		{Country("xx"), "Europe/Stockholm"},
+	} {
+		if got := tt.country.Location(); got == nil || got.String() != tt.want {
+			t.Fatalf("%q.Location() = %v, want %s", tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDST(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		loc         *time.Location
+		start, next string
+		startOffset string
+		nextOffset  string
+		slot        time.Duration
+	}{
+		{"Stockholm March", Stockholm, "2017-03-26T01:30:00", "2017-03-26T03:30:00", "+0100", "+0200", time.Hour},
+		{"Stockholm October", Stockholm, "2017-10-29T01:30:00", "2017-10-29T03:30:00", "+0200", "+0100", 3 * time.Hour},
+		{"Helsinki March", Helsinki, "2017-03-26T02:30:00", "2017-03-26T04:30:00", "+0200", "+0300", time.Hour},
+		{"Helsinki October", Helsinki, "2017-10-29T02:30:00", "2017-10-29T04:30:00", "+0300", "+0200", 3 * time.Hour},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			date := tt.start[:10] + "T00:00:00"
+
+			doc := `<Epg FromDate="` + date + `" UntilDate="` + date + `">` +
+				`<Day BroadcastDate="` + date + `"><Channel ChannelId="1">` +
+				`<Schedule ScheduleId="1" CalendarDate="` + tt.start + `" NextStart="` + tt.next + `">` +
+				`<Program VodStart="0001-01-01T00:00:00" LastCalendarDate="9999-12-31T23:59:59"/>` +
+				`</Schedule></Channel></Day></Epg>`
+
+			r, err := decode(strings.NewReader(doc), tt.loc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := r.Day().BroadcastDate.Location(); got != tt.loc {
+				t.Fatalf("BroadcastDate.Location() = %v, want %v", got, tt.loc)
+			}
+
+			s := r.Day().Channel("1").Schedules[0]
+
+			if got, want := s.CalendarDate.Format(xmlTimeFormat+" -0700"), tt.start+" "+tt.startOffset; got != want {
+				t.Fatalf("s.CalendarDate = %q, want %q", got, want)
+			}
+
+			if got, want := s.NextStart.Format(xmlTimeFormat+" -0700"), tt.next+" "+tt.nextOffset; got != want {
+				t.Fatalf("s.NextStart = %q, want %q", got, want)
+			}
+
+			if got := s.Slot(); got != tt.slot {
+				t.Fatalf("s.Slot() = %v, want %v", got, tt.slot)
+			}
+
+			if !s.Program.VodStart.IsZero() || !s.Program.LastCalendarDate.Equal(maxTime) {
+				t.Fatalf("sentinels = %v, %v, want zero and max time", s.Program.VodStart, s.Program.LastCalendarDate)
+			}
+		})
+	}
+}
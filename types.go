@@ -0,0 +1,133 @@
+package epg
+
+// ScheduleType is the type of a schedule, e.g. Live.
+// Unknown values are preserved as is, see Known
+type ScheduleType string
+
+const (
+	// ScheduleTape is a schedule of a recorded program
+	ScheduleTape ScheduleType = "Tape"
+
+	// ScheduleLive is a schedule of a live broadcast
+	ScheduleLive ScheduleType = "Live"
+)
+
+// IsLive reports whether the schedule is a live broadcast
+func (t ScheduleType) IsLive() bool {
+	return t == ScheduleLive
+}
+
+// Known reports whether the type is one of the ScheduleType constants
+func (t ScheduleType) Known() bool {
+	switch t {
+	case ScheduleTape, ScheduleLive:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProgramType is the type of a program, e.g. EpisodeProgram.
+// Unknown values are preserved as is, see Known
+type ProgramType string
+
+const (
+	// ProgramSingle is a program that is not part of a series
+	ProgramSingle ProgramType = "SingleProgram"
+
+	// ProgramEpisode is an episode of a series
+	ProgramEpisode ProgramType = "EpisodeProgram"
+)
+
+// IsEpisode reports whether the program is an episode of a series
+func (t ProgramType) IsEpisode() bool {
+	return t == ProgramEpisode
+}
+
+// Known reports whether the type is one of the ProgramType constants
+func (t ProgramType) Known() bool {
+	switch t {
+	case ProgramSingle, ProgramEpisode:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProgramClass is the class of a program, e.g. Sport.
+// Unknown values are preserved as is, see Known
+type ProgramClass string
+
+const (
+	// ClassRegular is a regular program
+	ClassRegular ProgramClass = "Regular"
+
+	// ClassSport is a sports program
+	ClassSport ProgramClass = "Sport"
+)
+
+// IsSport reports whether the program is a sports program
+func (c ProgramClass) IsSport() bool {
+	return c == ClassSport
+}
+
+// Known reports whether the class is one of the ProgramClass constants
+func (c ProgramClass) Known() bool {
+	switch c {
+	case ClassRegular, ClassSport:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProgramCategory is the category of a program, e.g. Film.
+// Unknown values are preserved as is, see Known
+type ProgramCategory string
+
+// Program categories
+const (
+	CategoryDocumentary    ProgramCategory = "Documentary"
+	CategoryEvent          ProgramCategory = "Event"
+	CategoryFilm           ProgramCategory = "Film"
+	CategoryGame           ProgramCategory = "Game"
+	CategoryMagazine       ProgramCategory = "Magazine"
+	CategoryOther          ProgramCategory = "Other"
+	CategoryScriptedSeries ProgramCategory = "ScriptedSeries"
+	CategoryStudio         ProgramCategory = "Studio"
+	CategoryUndefined      ProgramCategory = "Undefined"
+)
+
+// Known reports whether the category is one of the ProgramCategory constants
+func (c ProgramCategory) Known() bool {
+	switch c {
+	case CategoryDocumentary, CategoryEvent, CategoryFilm, CategoryGame, CategoryMagazine,
+		CategoryOther, CategoryScriptedSeries, CategoryStudio, CategoryUndefined:
+		return true
+	default:
+		return false
+	}
+}
+
+// ImageCategory is the category of an image, e.g. Cover.
+// Unknown values are preserved as is, see Known
+type ImageCategory string
+
+// Image categories
+const (
+	ImagePrimary ImageCategory = "Primary"
+	ImageCover   ImageCategory = "Cover"
+	ImageLogo    ImageCategory = "Logo"
+	ImageStudio  ImageCategory = "Studio"
+	ImageClips   ImageCategory = "Clips"
+)
+
+// Known reports whether the category is one of the ImageCategory constants
+func (c ImageCategory) Known() bool {
+	switch c {
+	case ImagePrimary, ImageCover, ImageLogo, ImageStudio, ImageClips:
+		return true
+	default:
+		return false
+	}
+}
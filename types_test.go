@@ -0,0 +1,87 @@
+package epg
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestTypeHelpers(t *testing.T) {
+	if !ScheduleLive.IsLive() || ScheduleTape.IsLive() {
+		t.Fatalf("IsLive does not match ScheduleLive")
+	}
+
+	if !ProgramEpisode.IsEpisode() || ProgramSingle.IsEpisode() {
+		t.Fatalf("IsEpisode does not match ProgramEpisode")
+	}
+
+	if !ClassSport.IsSport() || ClassRegular.IsSport() {
+		t.Fatalf("IsSport does not match ClassSport")
+	}
+}
+
+func TestTypeKnown(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		known bool
+		want  bool
+	}{
+		{"ScheduleLive", ScheduleLive.Known(), true},
+		{"ScheduleType(Replay)", ScheduleType("Replay").Known(), false},
+		{"ProgramSingle", ProgramSingle.Known(), true},
+		{"ProgramType(Clip)", ProgramType("Clip").Known(), false},
+		{"ClassRegular", ClassRegular.Known(), true},
+		{"ProgramClass(empty)", ProgramClass("").Known(), false},
+		{"CategoryScriptedSeries", CategoryScriptedSeries.Known(), true},
+		{"ProgramCategory(Series)", ProgramCategory("Series").Known(), false},
+		{"ImageClips", ImageClips.Known(), true},
+		{"ImageCategory(Banner)", ImageCategory("Banner").Known(), false},
+	} {
+		if tt.known != tt.want {
+			t.Fatalf("%s.Known() = %v, want %v", tt.name, tt.known, tt.want)
+		}
+	}
+}
+
+// TestTypesSchemaDrift fails if the fixtures contain values without constants
+func TestTypesSchemaDrift(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"finnish channel 12":    finnishChannel12ResponseXML,
+		"danish two days drama": danishTwoDaysDramaEPGResponseXML,
+		"swedish live sports":   swedishLiveSportsEPGResponseXML,
+		"swedish full day":      swedishFullDayEPGResponseXML,
+	} {
+		var r Response
+
+		if err := xml.Unmarshal(data, &r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, d := range r.Days {
+			for _, c := range d.Channels {
+				for _, s := range c.Schedules {
+					p := s.Program
+
+					for _, v := range []struct {
+						known bool
+						value string
+					}{
+						{s.Type.Known(), string(s.Type)},
+						{p.Type.Known(), string(p.Type)},
+						{p.Class.Known(), string(p.Class)},
+						{p.Category.Known(), string(p.Category)},
+					} {
+						if !v.known {
+							t.Fatalf("%s: unknown value %q in schedule %s", name, v.value, s.ID)
+						}
+					}
+
+					for _, m := range p.Images {
+						if !m.Category.Known() {
+							t.Fatalf("%s: unknown image category %q in schedule %s", name, m.Category, s.ID)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package epg
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Variant is the preferred variant of channels that are broadcast in both HD and SD
+type Variant int
+
+const (
+	// PreferHD keeps the HD variant of channels
+	PreferHD Variant = iota
+
+	// PreferSD keeps the SD variant of channels
+	PreferSD
+)
+
+// MergeVariants returns a copy of the day where the HD and SD variants of each
+// channel, like CMoreStars and CMoreStarsHD, are merged into the preferred one,
+// in the place of the first of them. Schedules of the same program at the same
+// start time are kept once, from the preferred variant, ordered by start time.
+// SD schedules that were also broadcast in HD are marked as AlsoAvailableInHD
+func (d Day) MergeVariants(prefer Variant) Day {
+	d = d.clone()
+
+	var (
+		infos = make([]ChannelInfo, len(d.Channels))
+		index = make(map[string]int, len(d.Channels))
+	)
+
+	for i, c := range d.Channels {
+		infos[i] = ChannelInfo{ID: c.ID, Name: c.Name, Title: c.Title}
+		index[c.ID] = i
+	}
+
+	pairVariants(infos)
+
+	// Use the known variants for channels that could not be paired by name or title
+	for i := range infos {
+		if infos[i].HDID != "" || infos[i].SDID != "" {
+			continue
+		}
+
+		j, ok := index[knownChannel(infos[i].ID).HDID]
+		if !ok || infos[j].HDID != "" || infos[j].SDID != "" {
+			continue
+		}
+
+		infos[i].HDID, infos[j].SDID = infos[j].ID, infos[i].ID
+	}
+
+	var (
+		channels = make([]Channel, 0, len(d.Channels))
+		merged   = map[string]bool{}
+	)
+
+	for i, c := range d.Channels {
+		if merged[c.ID] {
+			continue
+		}
+
+		sd, hd := -1, -1
+
+		switch {
+		case infos[i].HDID != "":
+			sd, hd = i, index[infos[i].HDID]
+		case infos[i].SDID != "":
+			sd, hd = index[infos[i].SDID], i
+		default:
+			channels = append(channels, c)
+			continue
+		}
+
+		merged[d.Channels[sd].ID], merged[d.Channels[hd].ID] = true, true
+
+		if prefer == PreferSD {
+			channels = append(channels, mergeSchedules(d.Channels[sd], d.Channels[hd], true))
+		} else {
+			channels = append(channels, mergeSchedules(d.Channels[hd], d.Channels[sd], false))
+		}
+	}
+
+	d.Channels = channels
+
+	return d
+}
+
+// MergeVariants returns a copy of the response with the HD and SD variants of
+// the channels of each day merged, see Day.MergeVariants
+func (r *Response) MergeVariants(prefer Variant) *Response {
+	c := r.clone()
+
+	for i := range c.Days {
+		c.Days[i] = c.Days[i].MergeVariants(prefer)
+	}
+
+	return c
+}
+
+// mergeSchedules adds the schedules of other that are not in c to c. If hd is
+// true, the schedules of c that are also in other are marked as AlsoAvailableInHD
+func mergeSchedules(c, other Channel, hd bool) Channel {
+	var (
+		schedules = c.Schedules
+		seen      = make(map[string]int, len(schedules))
+	)
+
+	for i, s := range schedules {
+		seen[scheduleKey(s)] = i
+	}
+
+	for _, s := range other.Schedules {
+		if i, ok := seen[scheduleKey(s)]; ok {
+			if hd {
+				schedules[i].AlsoAvailableInHD = true
+			}
+
+			continue
+		}
+
+		seen[scheduleKey(s)] = len(schedules)
+
+		schedules = append(schedules, s)
+	}
+
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return schedules[i].Start().Before(schedules[j].Start())
+	})
+
+	c.Schedules = schedules
+
+	return c
+}
+
+// scheduleKey identifies the program of a schedule, and its start time
+func scheduleKey(s Schedule) string {
+	id := s.Program.ID
+
+	if id == "" {
+		id = s.Program.Title
+	}
+
+	return id + "@" + strconv.FormatInt(s.Start().Unix(), 10)
+}
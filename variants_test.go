@@ -0,0 +1,121 @@
+package epg
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testVariantsDay() Day {
+	at := func(hour int, id, title string) Schedule {
+		return Schedule{
+			CalendarDate: Time{time.Date(2017, 1, 25, hour, 0, 0, 0, Stockholm)},
+			Program:      Program{ID: id, Title: title},
+		}
+	}
+
+	return Day{Channels: []Channel{
+		{ID: TV4, Name: "TV4", Title: "TV4", Schedules: []Schedule{at(19, "1", "Nyheterna")}},
+		{ID: CMoreStarsHD, Name: "CMoreStarsHD", Title: "C More Stars HD", Schedules: []Schedule{
+			at(20, "2", "Film"),
+			at(22, "3", "HD only"),
+		}},
+		{ID: CMoreStars, Name: "CMoreStars", Title: "C More Stars", Schedules: []Schedule{
+			at(18, "4", "SD only"),
+			at(20, "2", "Film"),
+		}},
+		{ID: CanalFilm1, Name: "CanalFilm1", Schedules: []Schedule{at(21, "", "First")}},
+		{ID: CanalHD, Name: "CanalHD", Schedules: []Schedule{at(21, "", "First")}},
+	}}
+}
+
+func TestDayMergeVariants(t *testing.T) {
+	for _, tt := range []struct {
+		prefer   Variant
+		channels string
+		stars    string
+		hd       string
+	}{
+		{PreferHD, "76,90,12", "SD only,Film,HD only", "false,false,false"},
+		{PreferSD, "76,89,8", "SD only,Film,HD only", "false,true,false"},
+	} {
+		d := testVariantsDay()
+
+		got := d.MergeVariants(tt.prefer)
+
+		var ids []string
+
+		for _, c := range got.Channels {
+			ids = append(ids, c.ID)
+		}
+
+		if strings.Join(ids, ",") != tt.channels {
+			t.Fatalf("channel IDs = %v, want %s", ids, tt.channels)
+		}
+
+		var titles, hd []string
+
+		for _, s := range got.Channels[1].Schedules {
+			titles = append(titles, s.Program.Title)
+
+			if s.AlsoAvailableInHD {
+				hd = append(hd, "true")
+			} else {
+				hd = append(hd, "false")
+			}
+		}
+
+		if strings.Join(titles, ",") != tt.stars {
+			t.Fatalf("schedules = %v, want %s", titles, tt.stars)
+		}
+
+		if strings.Join(hd, ",") != tt.hd {
+			t.Fatalf("AlsoAvailableInHD = %v, want %s", hd, tt.hd)
+		}
+
+		if got, want := len(got.Channels[2].Schedules), 1; got != want {
+			t.Fatalf("len(CanalFilm1/CanalHD schedules) = %d, want %d", got, want)
+		}
+
+		if got, want := len(d.Channels), 5; got != want || len(d.Channels[1].Schedules) != 2 {
+			t.Fatalf("MergeVariants modified the day")
+		}
+	}
+}
+
+func TestResponseMergeVariants(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(swedishFullDayEPGResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := r.MergeVariants(PreferHD)
+
+	before, after := r.Day().Channels, m.Day().Channels
+
+	if len(after) >= len(before) {
+		t.Fatalf("len(channels) = %d, want less than %d", len(after), len(before))
+	}
+
+	if got := m.Day().Channel(CMoreStars); got.ID != "" {
+		t.Fatalf("CMoreStars was not merged into CMoreStarsHD")
+	}
+
+	stars := m.Day().Channel(CMoreStarsHD)
+
+	if got, want := len(stars.Schedules), len(r.Day().Channel(CMoreStarsHD).Schedules); got < want {
+		t.Fatalf("len(stars.Schedules) = %d, want at least %d", got, want)
+	}
+
+	for i := 1; i < len(stars.Schedules); i++ {
+		if stars.Schedules[i].Start().Before(stars.Schedules[i-1].Start()) {
+			t.Fatalf("schedules are not ordered by start time")
+		}
+	}
+
+	if got := m.Day().Channel(TV4); len(got.Schedules) != len(r.Day().Channel(TV4).Schedules) {
+		t.Fatalf("TV4 schedules changed")
+	}
+}
@@ -0,0 +1,289 @@
+package epg
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xmltvTimeFormat is the format of the start and stop attributes in XMLTV
+const xmltvTimeFormat = "20060102150405 -0700"
+
+// xmltvRatingSystem is the rating system used for Program.Rating in XMLTV
+const xmltvRatingSystem = "C More"
+
+// emptyLogoID is used by the API for channels without a logo
+const emptyLogoID = "00000000-0000-0000-0000-000000000000"
+
+// XMLTVOptions are the options used when writing XMLTV
+type XMLTVOptions struct {
+	// Language is the language code of titles, descriptions and genres, e.g. "sv"
+	Language string
+
+	// ImageFormat is the format used for channel logos and program images (defaults to 164)
+	ImageFormat string
+
+	// ChannelID returns the XMLTV channel id for a channel (defaults to the channel ID)
+	ChannelID func(Channel) string
+}
+
+// WriteXMLTV writes the response as an XMLTV document to w.
+// Channels and schedules repeated across days are only written once.
+func (r *Response) WriteXMLTV(w io.Writer, opts XMLTVOptions) error {
+	if opts.ImageFormat == "" {
+		opts.ImageFormat = "164"
+	}
+
+	if opts.ChannelID == nil {
+		opts.ChannelID = func(c Channel) string { return c.ID }
+	}
+
+	tv := xmltvTV{GeneratorInfoName: "epg (https://github.com/TV4/epg)"}
+
+	var (
+		channels  = map[string]bool{}
+		schedules = map[string]bool{}
+	)
+
+	for _, d := range r.Days {
+		for _, c := range d.Channels {
+			id := opts.ChannelID(c)
+
+			if !channels[c.ID] {
+				channels[c.ID] = true
+				tv.Channels = append(tv.Channels, xmltvChannelFrom(id, c, opts))
+			}
+
+			for _, s := range c.Schedules {
+				if s.ID != "" && schedules[s.ID] {
+					continue
+				}
+
+				schedules[s.ID] = true
+
+				if p, ok := xmltvProgrammeFrom(id, s, opts); ok {
+					tv.Programmes = append(tv.Programmes, p)
+				}
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header+`<!DOCTYPE tv SYSTEM "xmltv.dtd">`+"\n"); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(tv); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+func xmltvChannelFrom(id string, c Channel, opts XMLTVOptions) xmltvChannel {
+	xc := xmltvChannel{ID: id}
+
+	for _, name := range []string{c.Title, c.Name} {
+		if name != "" {
+			xc.DisplayNames = append(xc.DisplayNames, xmltvText{Value: name})
+		}
+	}
+
+	for _, logoID := range []string{c.LogoID, c.LogoDarkID, c.LogoLightID} {
+		if logoID != "" && logoID != emptyLogoID {
+			xc.Icons = append(xc.Icons, xmltvIcon{Src: Image{ID: logoID}.URL(opts.ImageFormat).String()})
+		}
+	}
+
+	return xc
+}
+
+// xmltvProgrammeFrom maps a schedule to a programme, reporting false if it has no known start
+func xmltvProgrammeFrom(channelID string, s Schedule, opts XMLTVOptions) (xmltvProgramme, bool) {
+	start := s.Start()
+
+	if start.IsZero() {
+		return xmltvProgramme{}, false
+	}
+
+	p := s.Program
+
+	xp := xmltvProgramme{
+		Start:   start.Format(xmltvTimeFormat),
+		Channel: channelID,
+		Titles:  []xmltvText{{Lang: opts.Language, Value: p.Title}},
+		Date:    p.ProductionYear,
+	}
+
+	if stop := s.stop(); !stop.IsZero() {
+		xp.Stop = stop.Format(xmltvTimeFormat)
+	}
+
+	if p.EpisodeTitle != "" && p.EpisodeTitle != p.Title {
+		xp.SubTitles = []xmltvText{{Lang: opts.Language, Value: p.EpisodeTitle}}
+	}
+
+	if desc := p.synopsis(); desc != "" {
+		xp.Descs = []xmltvText{{Lang: opts.Language, Value: desc}}
+	}
+
+	if p.Directors != "" || p.Actors != "" {
+		xp.Credits = &xmltvCredits{
+			Directors: xmltvNames(p.Directors),
+			Actors:    xmltvNames(p.Actors),
+		}
+	}
+
+	if p.Genre != "" {
+		xp.Categories = append(xp.Categories, xmltvText{Lang: opts.Language, Value: p.Genre})
+	}
+
+	if p.Category != "" {
+		xp.Categories = append(xp.Categories, xmltvText{Lang: "en", Value: string(p.Category)})
+	}
+
+	if s.Type.IsLive() {
+		xp.Categories = append(xp.Categories, xmltvText{Lang: "en", Value: string(ScheduleLive)})
+	}
+
+	if p.Duration > 0 {
+		xp.Length = &xmltvLength{Units: "minutes", Value: strconv.Itoa(p.Duration)}
+	}
+
+	for _, m := range p.Images {
+		xp.Icons = append(xp.Icons, xmltvIcon{Src: m.URL(opts.ImageFormat).String()})
+	}
+
+	if ns := xmltvEpisodeNS(p); ns != "" {
+		xp.EpisodeNums = []xmltvEpisodeNum{{System: "xmltv_ns", Value: ns}}
+	}
+
+	if s.IsPremiere {
+		xp.Premiere = &xmltvText{}
+	}
+
+	if p.Rating != "" {
+		xp.Ratings = []xmltvRating{{System: xmltvRatingSystem, Value: string(p.Rating)}}
+	}
+
+	return xp, true
+}
+
+// synopsis returns the longest synopsis of the program
+func (p Program) synopsis() string {
+	for _, s := range []string{p.SynopsisLong, p.SynopsisMedium, p.SynopsisShort, p.SynopsisExtraShort} {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+
+	return ""
+}
+
+// xmltvEpisodeNS returns the zero-based xmltv_ns episode number, e.g. "1.11/31." for S02E12 of 31
+func xmltvEpisodeNS(p Program) string {
+	if p.SeasonNumber <= 0 && p.EpisodeNumber <= 0 {
+		return ""
+	}
+
+	var season, episode string
+
+	if p.SeasonNumber > 0 {
+		season = strconv.Itoa(p.SeasonNumber - 1)
+	}
+
+	if p.EpisodeNumber > 0 {
+		episode = strconv.Itoa(p.EpisodeNumber - 1)
+
+		if p.NumberOfEpisodes > 0 {
+			episode += "/" + strconv.Itoa(p.NumberOfEpisodes)
+		}
+	}
+
+	return season + "." + episode + "."
+}
+
+func xmltvNames(s string) []string {
+	var names []string
+
+	for _, n := range Names(s) {
+		if n != "" {
+			names = append(names, n)
+		}
+	}
+
+	return names
+}
+
+type xmltvTV struct {
+	XMLName           xml.Name         `xml:"tv"`
+	GeneratorInfoName string           `xml:"generator-info-name,attr,omitempty"`
+	Channels          []xmltvChannel   `xml:"channel"`
+	Programmes        []xmltvProgramme `xml:"programme"`
+	Unknown           []xmltvUnknown   `xml:",any"`
+}
+
+type xmltvChannel struct {
+	ID           string         `xml:"id,attr"`
+	DisplayNames []xmltvText    `xml:"display-name"`
+	Icons        []xmltvIcon    `xml:"icon"`
+	Unknown      []xmltvUnknown `xml:",any"`
+}
+
+type xmltvProgramme struct {
+	Start       string            `xml:"start,attr"`
+	Stop        string            `xml:"stop,attr,omitempty"`
+	Channel     string            `xml:"channel,attr"`
+	Titles      []xmltvText       `xml:"title"`
+	SubTitles   []xmltvText       `xml:"sub-title"`
+	Descs       []xmltvText       `xml:"desc"`
+	Credits     *xmltvCredits     `xml:"credits"`
+	Date        string            `xml:"date,omitempty"`
+	Categories  []xmltvText       `xml:"category"`
+	Length      *xmltvLength      `xml:"length"`
+	Icons       []xmltvIcon       `xml:"icon"`
+	EpisodeNums []xmltvEpisodeNum `xml:"episode-num"`
+	Premiere    *xmltvText        `xml:"premiere"`
+	Ratings     []xmltvRating     `xml:"rating"`
+	Unknown     []xmltvUnknown    `xml:",any"`
+}
+
+type xmltvText struct {
+	Lang  string `xml:"lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type xmltvIcon struct {
+	Src string `xml:"src,attr"`
+}
+
+type xmltvCredits struct {
+	Directors []string       `xml:"director"`
+	Actors    []string       `xml:"actor"`
+	Unknown   []xmltvUnknown `xml:",any"`
+}
+
+type xmltvLength struct {
+	Units string `xml:"units,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmltvEpisodeNum struct {
+	System string `xml:"system,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+type xmltvRating struct {
+	System string `xml:"system,attr,omitempty"`
+	Value  string `xml:"value"`
+}
+
+// xmltvUnknown is an element that is not supported when reading XMLTV
+type xmltvUnknown struct {
+	XMLName xml.Name
+}
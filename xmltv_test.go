@@ -0,0 +1,110 @@
+package epg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseWriteXMLTV(t *testing.T) {
+	r, err := decode(bytes.NewReader(finnishChannel12ResponseXML), Helsinki)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := r.WriteXMLTV(&buf, XMLTVOptions{Language: "fi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+
+	for _, want := range []string{
+		`<!DOCTYPE tv SYSTEM "xmltv.dtd">`,
+		`<channel id="12">`,
+		`<display-name>C More First HD</display-name>`,
+		`<icon src="https://img-cdn-cmore.b17g.services/6636a32b-629c-45a9-a546-505d5cfe8d33/164.img"></icon>`,
+		`<programme start="20170127080000 +0200" stop="20170127093500 +0200" channel="12">`,
+		`<title lang="fi">Sommeren &#39;92</title>`,
+		`<director>Kasper Barfoed</director>`,
+		`<actor>Mikkel Boe Følsgaard</actor>`,
+		`<date>2015</date>`,
+		`<category lang="fi">Draama</category>`,
+		`<category lang="en">Film</category>`,
+		`<length units="minutes">89</length>`,
+		`<icon src="https://img-cdn-cmore.b17g.services/db247816-3ebd-4cac-b5ac-486f64127cdb/164.img"></icon>`,
+		`<rating system="C More">`,
+		`<value>TURQUOISE</value>`,
+		`<sub-title lang="fi">Jennifer Lopez</sub-title>`,
+		`<episode-num system="xmltv_ns">1.11/31.</episode-num>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("XMLTV output does not contain %q", want)
+		}
+	}
+
+	// The logo without an ID must not be written
+	if strings.Contains(out, emptyLogoID) {
+		t.Fatalf("XMLTV output contains the empty logo ID")
+	}
+
+	var tv xmltvTV
+
+	if err := xml.Unmarshal(buf.Bytes(), &tv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(tv.Programmes), len(r.Day().Channel(CanalHD).Schedules); got != want {
+		t.Fatalf("len(tv.Programmes) = %d, want %d", got, want)
+	}
+}
+
+func TestXMLTVProgrammeFrom(t *testing.T) {
+	s := Schedule{
+		CalendarDate: Time{time.Date(2017, 1, 27, 20, 0, 0, 0, Stockholm)},
+		Type:         "Live",
+		IsPremiere:   true,
+		Program:      Program{Title: "Hockey", Duration: 150},
+	}
+
+	p, ok := xmltvProgrammeFrom("68", s, XMLTVOptions{})
+	if !ok {
+		t.Fatalf("xmltvProgrammeFrom returned false")
+	}
+
+	if got, want := p.Stop, "20170127223000 +0100"; got != want {
+		t.Fatalf("p.Stop = %q, want %q", got, want)
+	}
+
+	if p.Premiere == nil {
+		t.Fatalf("p.Premiere = nil, want premiere")
+	}
+
+	if got, want := p.Categories, []xmltvText{{Lang: "en", Value: "Live"}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("p.Categories = %v, want %v", got, want)
+	}
+
+	if _, ok := xmltvProgrammeFrom("68", Schedule{}, XMLTVOptions{}); ok {
+		t.Fatalf("xmltvProgrammeFrom returned true for a schedule without start")
+	}
+}
+
+func TestXMLTVEpisodeNS(t *testing.T) {
+	for _, tt := range []struct {
+		program Program
+		want    string
+	}{
+		{Program{}, ""},
+		{Program{SeasonNumber: 2, EpisodeNumber: 12, NumberOfEpisodes: 31}, "1.11/31."},
+		{Program{SeasonNumber: 1, EpisodeNumber: 1}, "0.0."},
+		{Program{EpisodeNumber: 4}, ".3."},
+		{Program{SeasonNumber: 3}, "2.."},
+	} {
+		if got := xmltvEpisodeNS(tt.program); got != tt.want {
+			t.Fatalf("xmltvEpisodeNS(%+v) = %q, want %q", tt.program, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package epg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XMLTVReadOptions are the options used when reading XMLTV
+type XMLTVReadOptions struct {
+	// Language is the preferred language code of titles, descriptions and genres, e.g. "sv"
+	Language string
+
+	// Location is used for broadcast days and times without offset (defaults to Stockholm)
+	Location *time.Location
+
+	// DayStart is the time of day broadcast days start at, e.g. 5 * time.Hour (defaults to midnight)
+	DayStart time.Duration
+}
+
+// XMLTVWarning is a part of an XMLTV document that could not be mapped to the Response
+type XMLTVWarning struct {
+	Channel string
+	Start   string
+	Element string
+	Message string
+}
+
+func (w XMLTVWarning) String() string {
+	s := "xmltv: " + w.Element
+
+	if w.Channel != "" {
+		s += " (channel " + strconv.Quote(w.Channel)
+
+		if w.Start != "" {
+			s += ", start " + strconv.Quote(w.Start)
+		}
+
+		s += ")"
+	}
+
+	return s + ": " + w.Message
+}
+
+// ReadXMLTV reads an XMLTV document into a Response, with the programmes grouped
+// into broadcast days. Parts of the document that can not be mapped are returned
+// as warnings, an error is only returned if the document could not be decoded.
+func ReadXMLTV(r io.Reader, opts XMLTVReadOptions) (*Response, []XMLTVWarning, error) {
+	if opts.Location == nil {
+		opts.Location = Stockholm
+	}
+
+	var tv xmltvTV
+
+	if err := xml.NewDecoder(r).Decode(&tv); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		warnings []XMLTVWarning
+		channels []Channel
+		index    = map[string]int{}
+		days     = map[time.Time]map[string][]Schedule{}
+	)
+
+	warn := func(channel, start, element, format string, args ...interface{}) {
+		warnings = append(warnings, XMLTVWarning{
+			Channel: channel,
+			Start:   start,
+			Element: element,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, u := range tv.Unknown {
+		warn("", "", u.XMLName.Local, "unsupported element")
+	}
+
+	for _, xc := range tv.Channels {
+		if _, ok := index[xc.ID]; ok {
+			warn(xc.ID, "", "channel", "duplicate channel")
+			continue
+		}
+
+		c, ws := xmltvChannelTo(xc)
+
+		warnings = append(warnings, ws...)
+		index[c.ID] = len(channels)
+		channels = append(channels, c)
+	}
+
+	for _, xp := range tv.Programmes {
+		s, ws, ok := xmltvProgrammeTo(xp, opts)
+
+		warnings = append(warnings, ws...)
+
+		if !ok {
+			continue
+		}
+
+		if _, ok := index[xp.Channel]; !ok {
+			warn(xp.Channel, xp.Start, "programme", "undeclared channel")
+
+			index[xp.Channel] = len(channels)
+			channels = append(channels, Channel{ID: xp.Channel})
+		}
+
+		date := broadcastDate(s.CalendarDate.Time, opts.Location, opts.DayStart)
+
+		if days[date] == nil {
+			days[date] = map[string][]Schedule{}
+		}
+
+		days[date][xp.Channel] = append(days[date][xp.Channel], s)
+	}
+
+	dates := make([]time.Time, 0, len(days))
+
+	for date := range days {
+		dates = append(dates, date)
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	resp := &Response{}
+
+	for _, date := range dates {
+		d := Day{BroadcastDate: Time{date}}
+
+		for _, c := range channels {
+			c.Schedules = days[date][c.ID]
+
+			sort.SliceStable(c.Schedules, func(i, j int) bool {
+				return c.Schedules[i].CalendarDate.Before(c.Schedules[j].CalendarDate.Time)
+			})
+
+			d.Channels = append(d.Channels, c)
+		}
+
+		resp.Days = append(resp.Days, d)
+	}
+
+	if len(dates) > 0 {
+		resp.FromDate = Time{dates[0]}
+		resp.UntilDate = Time{dates[len(dates)-1]}
+	}
+
+	return resp, warnings, nil
+}
+
+// broadcastDate returns midnight of the broadcast day that t belongs to
+func broadcastDate(t time.Time, loc *time.Location, dayStart time.Duration) time.Time {
+	y, m, d := t.In(loc).Add(-dayStart).Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+func xmltvChannelTo(xc xmltvChannel) (Channel, []XMLTVWarning) {
+	var warnings []XMLTVWarning
+
+	warn := func(element, format string, args ...interface{}) {
+		warnings = append(warnings, XMLTVWarning{
+			Channel: xc.ID,
+			Element: element,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	c := Channel{ID: xc.ID}
+
+	for i, n := range xc.DisplayNames {
+		switch i {
+		case 0:
+			c.Title = n.Value
+		case 1:
+			c.Name = n.Value
+		default:
+			warn("display-name", "ignored additional name %q", n.Value)
+		}
+	}
+
+	for i, icon := range xc.Icons {
+		id, ok := xmltvImageID(icon.Src)
+
+		switch {
+		case !ok:
+			warn("icon", "unsupported image URL %q", icon.Src)
+		case i == 0:
+			c.LogoID = id
+		case i == 1:
+			c.LogoDarkID = id
+		case i == 2:
+			c.LogoLightID = id
+		default:
+			warn("icon", "ignored additional logo %q", icon.Src)
+		}
+	}
+
+	for _, u := range xc.Unknown {
+		warn(u.XMLName.Local, "unsupported element")
+	}
+
+	return c, warnings
+}
+
+// xmltvProgrammeTo maps a programme to a schedule, reporting false if its start could not be parsed
+func xmltvProgrammeTo(xp xmltvProgramme, opts XMLTVReadOptions) (Schedule, []XMLTVWarning, bool) {
+	var warnings []XMLTVWarning
+
+	warn := func(element, format string, args ...interface{}) {
+		warnings = append(warnings, XMLTVWarning{
+			Channel: xp.Channel,
+			Start:   xp.Start,
+			Element: element,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	start, err := parseXMLTVTime(xp.Start, opts.Location)
+	if err != nil {
+		warn("programme", "invalid start: %v", err)
+
+		return Schedule{}, warnings, false
+	}
+
+	s := Schedule{CalendarDate: Time{start}, IsPremiere: xp.Premiere != nil}
+
+	if xp.Stop != "" {
+		stop, err := parseXMLTVTime(xp.Stop, opts.Location)
+		if err != nil {
+			warn("programme", "invalid stop: %v", err)
+		} else {
+			s.NextStart = Time{stop}
+		}
+	}
+
+	p := &s.Program
+
+	if title, ok := xmltvPreferred(xp.Titles, opts.Language); ok {
+		p.Title = title.Value
+	} else {
+		warn("title", "missing title")
+	}
+
+	if title, ok := xmltvPreferred(xp.SubTitles, opts.Language); ok {
+		p.EpisodeTitle = title.Value
+	}
+
+	if desc, ok := xmltvPreferred(xp.Descs, opts.Language); ok {
+		p.SynopsisLong = desc.Value
+	}
+
+	for _, texts := range [][]xmltvText{xp.Titles, xp.SubTitles, xp.Descs} {
+		if len(texts) > 1 {
+			warn("programme", "ignored %d additional translations", len(texts)-1)
+		}
+	}
+
+	if xp.Credits != nil {
+		p.Directors = strings.Join(xp.Credits.Directors, ", ")
+		p.Actors = strings.Join(xp.Credits.Actors, ", ")
+
+		for _, u := range xp.Credits.Unknown {
+			warn(u.XMLName.Local, "unsupported credit")
+		}
+	}
+
+	if xp.Date != "" {
+		if len(xp.Date) >= 4 {
+			p.ProductionYear = xp.Date[:4]
+		} else {
+			warn("date", "invalid date %q", xp.Date)
+		}
+	}
+
+	for _, c := range xp.Categories {
+		switch {
+		case c.Lang == "en" && c.Value == string(ScheduleLive):
+			s.Type = ScheduleLive
+		case p.Genre == "" && (c.Lang != "en" || opts.Language == "en"):
+			p.Genre = c.Value
+		case p.Category == "":
+			p.Category = ProgramCategory(c.Value)
+		default:
+			warn("category", "ignored category %q", c.Value)
+		}
+	}
+
+	if xp.Length != nil {
+		if d, err := xmltvLengthMinutes(*xp.Length); err != nil {
+			warn("length", "%v", err)
+		} else {
+			p.Duration = d
+		}
+	}
+
+	for _, icon := range xp.Icons {
+		if id, ok := xmltvImageID(icon.Src); ok {
+			p.Images = append(p.Images, Image{ID: id})
+		} else {
+			warn("icon", "unsupported image URL %q", icon.Src)
+		}
+	}
+
+	for _, n := range xp.EpisodeNums {
+		if n.System != "xmltv_ns" {
+			warn("episode-num", "unsupported system %q", n.System)
+			continue
+		}
+
+		if err := parseXMLTVEpisodeNS(n.Value, p); err != nil {
+			warn("episode-num", "%v", err)
+		}
+	}
+
+	for _, r := range xp.Ratings {
+		if r.System == xmltvRatingSystem && p.Rating == "" {
+			p.Rating = Rating(r.Value)
+		} else {
+			warn("rating", "unsupported rating %q (system %q)", r.Value, r.System)
+		}
+	}
+
+	for _, u := range xp.Unknown {
+		warn(u.XMLName.Local, "unsupported element")
+	}
+
+	return s, warnings, true
+}
+
+// xmltvPreferred returns the text in the given language, or the first text
+func xmltvPreferred(texts []xmltvText, lang string) (xmltvText, bool) {
+	if len(texts) == 0 {
+		return xmltvText{}, false
+	}
+
+	for _, t := range texts {
+		if lang != "" && t.Lang == lang {
+			return t, true
+		}
+	}
+
+	return texts[0], true
+}
+
+// xmltvImageID returns the image ID of an ImageBaseURL image URL, e.g. https://img-cdn-cmore.b17g.services/:id/:format.img
+func xmltvImageID(src string) (string, bool) {
+	prefix := ImageBaseURL.String() + "/"
+
+	if !strings.HasPrefix(src, prefix) {
+		return "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(src, prefix), "/")
+
+	if len(parts) != 2 || parts[0] == "" || !strings.HasSuffix(parts[1], ".img") {
+		return "", false
+	}
+
+	return parts[0], true
+}
+
+func xmltvLengthMinutes(l xmltvLength) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(l.Value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid length %q", l.Value)
+	}
+
+	switch l.Units {
+	case "seconds":
+		return n / 60, nil
+	case "minutes":
+		return n, nil
+	case "hours":
+		return n * 60, nil
+	default:
+		return 0, fmt.Errorf("unsupported units %q", l.Units)
+	}
+}
+
+// parseXMLTVTime parses an XMLTV time like "20170127080000 +0100", where the
+// offset and any trailing fields may be left out. Times without offset are in loc.
+func parseXMLTVTime(s string, loc *time.Location) (time.Time, error) {
+	value, offset := strings.TrimSpace(s), ""
+
+	if i := strings.IndexAny(value, " +-"); i >= 0 {
+		value, offset = value[:i], strings.TrimSpace(value[i:])
+	}
+
+	layout := "20060102150405"
+
+	if len(value) < 4 || len(value) > len(layout) || len(value)%2 != 0 {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+
+	layout = layout[:len(value)]
+
+	switch offset {
+	case "":
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", s)
+		}
+
+		return t, nil
+	case "UTC", "GMT", "Z":
+		offset = "+0000"
+	}
+
+	t, err := time.Parse(layout+" -0700", value+" "+offset)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+
+	return t.In(loc), nil
+}
+
+// parseXMLTVEpisodeNS parses a zero-based xmltv_ns episode number, e.g. "1.11/31." into p
+func parseXMLTVEpisodeNS(s string, p *Program) error {
+	parts := strings.Split(strings.Join(strings.Fields(s), ""), ".")
+
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid xmltv_ns %q", s)
+	}
+
+	number := func(v string) (int, int, error) {
+		if v == "" {
+			return 0, 0, nil
+		}
+
+		var total int
+
+		if i := strings.Index(v, "/"); i >= 0 {
+			t, err := strconv.Atoi(v[i+1:])
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid xmltv_ns %q", s)
+			}
+
+			v, total = v[:i], t
+		}
+
+		if v == "" {
+			return 0, total, nil
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid xmltv_ns %q", s)
+		}
+
+		return n + 1, total, nil
+	}
+
+	season, _, err := number(parts[0])
+	if err != nil {
+		return err
+	}
+
+	episode, episodes, err := number(parts[1])
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := number(parts[2]); err != nil {
+		return err
+	}
+
+	p.SeasonNumber = season
+	p.EpisodeNumber = episode
+	p.NumberOfEpisodes = episodes
+
+	return nil
+}
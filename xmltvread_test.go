@@ -0,0 +1,283 @@
+package epg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadXMLTV(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE tv SYSTEM "xmltv.dtd">
+<tv>
+  <channel id="ext.1">
+    <display-name>External One</display-name>
+    <url>https://example.com</url>
+  </channel>
+  <programme start="20170125230000 +0000" stop="20170126010000 +0000" channel="ext.1">
+    <title lang="en">Late Show</title>
+    <title lang="sv">Sen show</title>
+    <sub-title lang="sv">Avsnitt</sub-title>
+    <desc lang="sv">Beskrivning</desc>
+    <credits>
+      <director>Jane Doe</director>
+      <actor role="Host">John Doe</actor>
+      <actor>Jim Doe</actor>
+      <writer>Joe Doe</writer>
+    </credits>
+    <date>20150101</date>
+    <category lang="sv">Drama</category>
+    <category lang="en">Series</category>
+    <category lang="en">Live</category>
+    <length units="hours">2</length>
+    <episode-num system="xmltv_ns">1 . 11/31 . 0/1</episode-num>
+    <episode-num system="onscreen">S02E12</episode-num>
+    <premiere/>
+    <rating system="VCHIP"><value>TV-14</value></rating>
+  </programme>
+  <programme start="201701260600" channel="ext.1">
+    <title>Morning</title>
+  </programme>
+  <programme start="not a time" channel="ext.1">
+    <title>Broken</title>
+  </programme>
+  <programme start="20170126070000 +0100" channel="ext.2">
+    <title>Undeclared</title>
+  </programme>
+</tv>`
+
+	r, warnings, err := ReadXMLTV(strings.NewReader(doc), XMLTVReadOptions{
+		Language: "sv",
+		Location: Stockholm,
+		DayStart: 5 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := len(r.Days), 2; got != want {
+		t.Fatalf("len(r.Days) = %d, want %d", got, want)
+	}
+
+	if got, want := r.FromDate.Format("2006-01-02"), "2017-01-25"; got != want {
+		t.Fatalf("r.FromDate = %q, want %q", got, want)
+	}
+
+	if got, want := r.UntilDate.Format("2006-01-02"), "2017-01-26"; got != want {
+		t.Fatalf("r.UntilDate = %q, want %q", got, want)
+	}
+
+	c := r.Day("2017-01-25").Channel("ext.1")
+
+	if got, want := c.Title, "External One"; got != want {
+		t.Fatalf("c.Title = %q, want %q", got, want)
+	}
+
+	if got, want := len(c.Schedules), 1; got != want {
+		t.Fatalf("len(c.Schedules) = %d, want %d", got, want)
+	}
+
+	s := c.Schedules[0]
+
+	if got, want := s.CalendarDate.Time, time.Date(2017, 1, 26, 0, 0, 0, 0, Stockholm); !got.Equal(want) || got.Location() != Stockholm {
+		t.Fatalf("s.CalendarDate = %v, want %v", got, want)
+	}
+
+	if got, want := s.NextStart.Time, time.Date(2017, 1, 26, 2, 0, 0, 0, Stockholm); !got.Equal(want) {
+		t.Fatalf("s.NextStart = %v, want %v", got, want)
+	}
+
+	if !s.IsPremiere || s.Type != "Live" {
+		t.Fatalf("s.IsPremiere = %v, s.Type = %q, want premiere live", s.IsPremiere, s.Type)
+	}
+
+	p := s.Program
+
+	for _, tt := range []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"Title", p.Title, "Sen show"},
+		{"EpisodeTitle", p.EpisodeTitle, "Avsnitt"},
+		{"SynopsisLong", p.SynopsisLong, "Beskrivning"},
+		{"Directors", p.Directors, "Jane Doe"},
+		{"Actors", p.Actors, "John Doe, Jim Doe"},
+		{"ProductionYear", p.ProductionYear, "2015"},
+		{"Genre", p.Genre, "Drama"},
+		{"Category", p.Category, ProgramCategory("Series")},
+		{"Duration", p.Duration, 120},
+		{"SeasonNumber", p.SeasonNumber, 2},
+		{"EpisodeNumber", p.EpisodeNumber, 12},
+		{"NumberOfEpisodes", p.NumberOfEpisodes, 31},
+		{"Rating", p.Rating, Rating("")},
+	} {
+		if tt.got != tt.want {
+			t.Fatalf("p.%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	next := r.Day("2017-01-26")
+
+	if got, want := next.Channel("ext.1").Schedules[0].Program.Title, "Morning"; got != want {
+		t.Fatalf("title = %q, want %q", got, want)
+	}
+
+	if got, want := next.Channel("ext.2").Schedules[0].Program.Title, "Undeclared"; got != want {
+		t.Fatalf("title = %q, want %q", got, want)
+	}
+
+	var messages []string
+
+	for _, w := range warnings {
+		messages = append(messages, w.String())
+	}
+
+	for _, want := range []string{
+		`xmltv: url (channel "ext.1"): unsupported element`,
+		`xmltv: programme (channel "ext.1", start "20170125230000 +0000"): ignored 1 additional translations`,
+		`xmltv: writer (channel "ext.1", start "20170125230000 +0000"): unsupported credit`,
+		`xmltv: episode-num (channel "ext.1", start "20170125230000 +0000"): unsupported system "onscreen"`,
+		`xmltv: rating (channel "ext.1", start "20170125230000 +0000"): unsupported rating "TV-14" (system "VCHIP")`,
+		`xmltv: programme (channel "ext.1", start "not a time"): invalid start: invalid time "not a time"`,
+		`xmltv: programme (channel "ext.2", start "20170126070000 +0100"): undeclared channel`,
+	} {
+		if !containsString(messages, want) {
+			t.Fatalf("warnings %q do not contain %q", messages, want)
+		}
+	}
+
+	if got, want := len(messages), 7; got != want {
+		t.Fatalf("len(warnings) = %d, want %d: %q", got, want, messages)
+	}
+}
+
+func TestReadXMLTVInvalid(t *testing.T) {
+	if _, _, err := ReadXMLTV(strings.NewReader("<tv>"), XMLTVReadOptions{}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestReadXMLTVRoundTrip(t *testing.T) {
+	var r Response
+
+	if err := xml.Unmarshal(swedishFullDayEPGResponseXML, &r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := r.WriteXMLTV(&buf, XMLTVOptions{Language: "sv"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, warnings, err := ReadXMLTV(&buf, XMLTVReadOptions{Language: "sv", DayStart: 5 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(warnings) > 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+
+	want := r.Day().Channel(TV4)
+	tv4 := got.Day("2017-01-25").Channel(TV4)
+
+	// Logos without an ID are not written, so the dark logo is read back as the first one
+	if tv4.Title != want.Title || tv4.Name != want.Name || tv4.LogoID != want.LogoDarkID {
+		t.Fatalf("tv4 = %+v, want %+v", tv4, want)
+	}
+
+	if got, want := len(tv4.Schedules), len(want.Schedules); got != want {
+		t.Fatalf("len(tv4.Schedules) = %d, want %d", got, want)
+	}
+
+	for i, s := range tv4.Schedules {
+		w := want.Schedules[i]
+
+		if !s.CalendarDate.Equal(w.CalendarDate.Time) {
+			t.Fatalf("s.CalendarDate = %v, want %v", s.CalendarDate, w.CalendarDate)
+		}
+
+		if s.Program.Title != w.Program.Title || s.Program.Genre != w.Program.Genre || s.Program.Duration != w.Program.Duration {
+			t.Fatalf("s.Program = %+v, want %+v", s.Program, w.Program)
+		}
+
+		if s.Program.SeasonNumber != w.Program.SeasonNumber || s.Program.EpisodeNumber != w.Program.EpisodeNumber {
+			t.Fatalf("episode = S%dE%d, want S%dE%d", s.Program.SeasonNumber, s.Program.EpisodeNumber, w.Program.SeasonNumber, w.Program.EpisodeNumber)
+		}
+
+		if s.Program.Rating != w.Program.Rating || s.IsPremiere != w.IsPremiere {
+			t.Fatalf("s = %+v, want %+v", s, w)
+		}
+	}
+}
+
+func TestParseXMLTVTime(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"20170127080000 +0200", time.Date(2017, 1, 27, 7, 0, 0, 0, Stockholm)},
+		{"20170127080000+0100", time.Date(2017, 1, 27, 8, 0, 0, 0, Stockholm)},
+		{"20170127080000 UTC", time.Date(2017, 1, 27, 9, 0, 0, 0, Stockholm)},
+		{"201707010800", time.Date(2017, 7, 1, 8, 0, 0, 0, Stockholm)},
+		{"20170127", time.Date(2017, 1, 27, 0, 0, 0, 0, Stockholm)},
+	} {
+		got, err := parseXMLTVTime(tt.value, Stockholm)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !got.Equal(tt.want) {
+			t.Fatalf("parseXMLTVTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "2017012", "20170127080000 +01", "201701270800000"} {
+		if _, err := parseXMLTVTime(value, Stockholm); err == nil {
+			t.Fatalf("parseXMLTVTime(%q) returned no error", value)
+		}
+	}
+}
+
+func TestParseXMLTVEpisodeNS(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  Program
+	}{
+		{"1.11/31.", Program{SeasonNumber: 2, EpisodeNumber: 12, NumberOfEpisodes: 31}},
+		{"0.0.", Program{SeasonNumber: 1, EpisodeNumber: 1}},
+		{".3.", Program{EpisodeNumber: 4}},
+		{"2..", Program{SeasonNumber: 3}},
+		{" 0/2 . /10 . 0/1 ", Program{SeasonNumber: 1, NumberOfEpisodes: 10}},
+	} {
+		var p Program
+
+		if err := parseXMLTVEpisodeNS(tt.value, &p); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if p.SeasonNumber != tt.want.SeasonNumber || p.EpisodeNumber != tt.want.EpisodeNumber || p.NumberOfEpisodes != tt.want.NumberOfEpisodes {
+			t.Fatalf("parseXMLTVEpisodeNS(%q) = %+v, want %+v", tt.value, p, tt.want)
+		}
+	}
+
+	for _, value := range []string{"1.2", "a.b.", "-2.0."} {
+		if err := parseXMLTVEpisodeNS(value, &Program{}); err == nil {
+			t.Fatalf("parseXMLTVEpisodeNS(%q) returned no error", value)
+		}
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}